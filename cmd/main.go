@@ -5,22 +5,33 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/vitormoschetta/go-adk/internal/config"
 	"github.com/vitormoschetta/go-adk/internal/handler"
 	"github.com/vitormoschetta/go-adk/internal/server"
 )
 
+const (
+	defaultShutdownTimeout = 15 * time.Second
+	minShutdownTimeout     = 5 * time.Second
+	maxShutdownTimeout     = 30 * time.Second
+)
+
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: .env file not found or could not be loaded")
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	watchForReload(cfg)
+
 	// Criar servidor
-	srv, err := server.NewServer(ctx)
+	srv, err := server.NewServer(ctx, cfg)
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
 	}
@@ -29,8 +40,69 @@ func main() {
 	h := handler.NewHandler(srv)
 
 	// Configurar rotas com os handlers
-	srv.SetupRouter(h.HandleRoot, h.HandleHealth, h.HandleChat, h.HandleTools)
+	srv.SetupRouter(h.HandleRoot, h.HandleHealth, h.HandleChat, h.HandleChatStream, h.HandleTools, h.HandlePowChallenge, h.HandleDeleteSession, h.HandleMetrics, h.HandleAgents, h.HandleChatStreamSSE, h.HandleChatStreamWS, h.HandleClusterStatus, h.HandleFeatures)
 
-	// Iniciar servidor
+	// Iniciar servidor (não bloqueia)
 	srv.Start(ctx)
+
+	// Aguardar sinal de interrupção
+	<-ctx.Done()
+	log.Println("\n🛑 Shutting down server...")
+
+	shutdownTimeout := shutdownTimeoutFromConfig(cfg)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("❌ Server shutdown error: %v", err)
+	}
+
+	if err := srv.SessionManager.Close(shutdownCtx); err != nil {
+		log.Printf("⚠️  Session drain did not finish cleanly: %v", err)
+	}
+
+	log.Println("✅ Server stopped gracefully")
+}
+
+// watchForReload recarrega cfg a cada SIGHUP recebido, sem reiniciar o
+// processo - útil para aplicar mudanças de .env/feature flags em produção
+// sem derrubar conexões em andamento.
+func watchForReload(cfg *config.Config) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		for range sighup {
+			if err := cfg.Reload(); err != nil {
+				log.Printf("⚠️  Failed to reload configuration: %v", err)
+				continue
+			}
+			log.Println("🔄 Configuration reloaded")
+		}
+	}()
+}
+
+// shutdownTimeoutFromConfig lê SHUTDOWN_TIMEOUT (ex: "20s"), limitando o
+// valor entre minShutdownTimeout e maxShutdownTimeout, e caindo para
+// defaultShutdownTimeout quando ausente ou inválido.
+func shutdownTimeoutFromConfig(cfg *config.Config) time.Duration {
+	v := cfg.Get("SHUTDOWN_TIMEOUT")
+	if v == "" {
+		return defaultShutdownTimeout
+	}
+
+	parsed, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("Warning: invalid SHUTDOWN_TIMEOUT %q, using default of %s", v, defaultShutdownTimeout)
+		return defaultShutdownTimeout
+	}
+
+	switch {
+	case parsed < minShutdownTimeout:
+		return minShutdownTimeout
+	case parsed > maxShutdownTimeout:
+		return maxShutdownTimeout
+	default:
+		return parsed
+	}
 }
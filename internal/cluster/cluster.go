@@ -0,0 +1,295 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"github.com/hashicorp/serf/serf"
+	"github.com/soheilhy/cmux"
+
+	sessionstore "github.com/vitormoschetta/go-adk/internal/session"
+)
+
+// internalApplyPath é o endpoint HTTP interno usado por forwardPut para
+// encaminhar escritas ao líder Raft. Registrado condicionalmente por
+// server.SetupRouter quando o servidor roda em modo cluster.
+const internalApplyPath = "/internal/cluster/apply"
+
+// virtualNodesPerMember controla quantos pontos cada nó ocupa no anel de
+// hash consistente - mais pontos espalham as sessões mais uniformemente
+// entre os nós quando o cluster cresce ou encolhe.
+const virtualNodesPerMember = 100
+
+// Cluster mantém a associação de um nó com o resto do cluster: Serf para
+// descoberta/membership e Raft para replicar o histórico das sessões. HTTP e
+// Raft compartilham BindAddr através do cmux.
+type Cluster struct {
+	cfg  Config
+	Serf *serf.Serf
+	Raft *raft.Raft
+
+	mux cmux.CMux
+}
+
+// New liga BindAddr, multiplexa-o entre HTTP e Raft via cmux, inicia o nó
+// Raft (formatando o cluster quando cfg.Bootstrap) e entra no gossip do Serf
+// (anunciando-se e, se cfg.Join não for vazio, tentando se juntar a ele).
+// Retorna também o net.Listener que o chamador deve usar para servir HTTP -
+// ListenAndServe não pode ser usado porque a porta já está ligada aqui.
+func New(cfg Config, store sessionstore.Store) (*Cluster, net.Listener, error) {
+	ln, err := net.Listen("tcp", cfg.BindAddr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster: failed to bind %s: %w", cfg.BindAddr, err)
+	}
+
+	mux := cmux.New(ln)
+	raftLn := newRaftStreamLayer(mux)
+	httpLn := mux.Match(cmux.Any())
+
+	if err := os.MkdirAll(cfg.RaftDir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("cluster: failed to create raft dir %s: %w", cfg.RaftDir, err)
+	}
+
+	logStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.RaftDir, "raft-log.boltdb"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster: failed to open raft log store: %w", err)
+	}
+
+	snapStore, err := raft.NewFileSnapshotStore(cfg.RaftDir, 2, os.Stderr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster: failed to create raft snapshot store: %w", err)
+	}
+
+	transport := raft.NewNetworkTransport(raftLn, 3, 10*time.Second, os.Stderr)
+
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(cfg.NodeID)
+
+	r, err := raft.NewRaft(raftConfig, NewFSM(store), logStore, logStore, snapStore, transport)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster: failed to create raft node: %w", err)
+	}
+
+	if cfg.Bootstrap {
+		f := r.BootstrapCluster(raft.Configuration{
+			Servers: []raft.Server{{ID: raftConfig.LocalID, Address: transport.LocalAddr()}},
+		})
+		if err := f.Error(); err != nil {
+			log.Printf("cluster: bootstrap skipped (likely already bootstrapped): %v", err)
+		}
+	}
+
+	serfHost, serfPort, err := net.SplitHostPort(cfg.SerfAddr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster: invalid CLUSTER_SERF_ADDR %q: %w", cfg.SerfAddr, err)
+	}
+	serfConfig := serf.DefaultConfig()
+	serfConfig.NodeName = cfg.NodeID
+	serfConfig.MemberlistConfig.BindAddr = serfHost
+	if _, err := fmt.Sscanf(serfPort, "%d", &serfConfig.MemberlistConfig.BindPort); err != nil {
+		return nil, nil, fmt.Errorf("cluster: invalid port in CLUSTER_SERF_ADDR %q: %w", cfg.SerfAddr, err)
+	}
+	serfConfig.Tags = map[string]string{"rpc_addr": cfg.BindAddr}
+
+	s, err := serf.Create(serfConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster: failed to start serf: %w", err)
+	}
+
+	if len(cfg.Join) > 0 {
+		if _, err := s.Join(cfg.Join, true); err != nil {
+			log.Printf("cluster: failed to join existing cluster via %v: %v", cfg.Join, err)
+		}
+	}
+
+	c := &Cluster{cfg: cfg, Serf: s, Raft: r, mux: mux}
+
+	go func() {
+		if err := mux.Serve(); err != nil {
+			log.Printf("cluster: cmux stopped: %v", err)
+		}
+	}()
+
+	return c, httpLn, nil
+}
+
+// Shutdown sai do gossip do Serf e desliga o nó Raft. Deve ser chamado
+// depois que o HTTP server já parou de aceitar conexões.
+func (c *Cluster) Shutdown() {
+	if err := c.Serf.Leave(); err != nil {
+		log.Printf("cluster: error leaving serf: %v", err)
+	}
+	c.Serf.Shutdown()
+
+	if err := c.Raft.Shutdown().Error(); err != nil {
+		log.Printf("cluster: error shutting down raft: %v", err)
+	}
+}
+
+// IsLeader indica se este nó é o líder Raft atual - só o líder aplica
+// escritas diretamente no log (ver applyLocal); nos demais nós, ApplyPut as
+// encaminha ao líder.
+func (c *Cluster) IsLeader() bool {
+	return c.Raft.State() == raft.Leader
+}
+
+// ApplyPut replica um Record de sessão para todo o cluster via Raft. Raft só
+// aceita Apply no nó líder, então um nó seguidor encaminha a escrita para o
+// líder via forwardPut em vez de falhar - o dono da sessão (ver Owner) não é
+// necessariamente o líder Raft, e exigir que fossem o mesmo nó acoplaria o
+// anel de hash consistente à eleição do Raft sem necessidade.
+func (c *Cluster) ApplyPut(key string, rec *sessionstore.Record, ttl time.Duration) error {
+	if !c.IsLeader() {
+		return c.forwardPut(key, rec, ttl)
+	}
+	return c.applyLocal(key, rec, ttl)
+}
+
+// applyLocal executa o Apply no log do Raft deste nó - só tem sucesso quando
+// chamado no nó líder.
+func (c *Cluster) applyLocal(key string, rec *sessionstore.Record, ttl time.Duration) error {
+	data, err := json.Marshal(putCommand{Key: key, Record: *rec, TTL: ttl})
+	if err != nil {
+		return fmt.Errorf("cluster: failed to encode put command: %w", err)
+	}
+
+	future := c.Raft.Apply(data, 5*time.Second)
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("cluster: raft apply failed: %w", err)
+	}
+	if applyErr, ok := future.Response().(error); ok && applyErr != nil {
+		return fmt.Errorf("cluster: fsm apply failed: %w", applyErr)
+	}
+	return nil
+}
+
+// forwardPut encaminha o put ao líder Raft atual via HTTP, em internalApplyPath.
+// c.Raft.Leader() retorna o mesmo BindAddr que o líder usa para servir HTTP
+// (cmux multiplexa ambos na mesma porta - ver New), então o endereço do líder
+// já é um destino HTTP válido sem precisar de um mapeamento separado.
+func (c *Cluster) forwardPut(key string, rec *sessionstore.Record, ttl time.Duration) error {
+	leader := c.Raft.Leader()
+	if leader == "" {
+		return fmt.Errorf("cluster: no raft leader available to forward put")
+	}
+
+	data, err := json.Marshal(putCommand{Key: key, Record: *rec, TTL: ttl})
+	if err != nil {
+		return fmt.Errorf("cluster: failed to encode put command: %w", err)
+	}
+
+	url := fmt.Sprintf("http://%s%s", leader, internalApplyPath)
+	httpClient := http.Client{Timeout: 5 * time.Second}
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("cluster: failed to forward put to leader %s: %w", leader, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cluster: leader %s rejected forwarded put: %s", leader, resp.Status)
+	}
+	return nil
+}
+
+// HandleApply recebe, no líder, os puts encaminhados por forwardPut de outros
+// nós e os aplica localmente ao Raft.
+func (c *Cluster) HandleApply(w http.ResponseWriter, r *http.Request) {
+	var cmd putCommand
+	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+		http.Error(w, "invalid put command", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.applyLocal(cmd.Key, &cmd.Record, cmd.TTL); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Owner retorna o endereço RPC (host:porta HTTP/cmux) do nó responsável pela
+// sessionID segundo um anel de hash consistente sobre os membros Serf vivos,
+// e se esse nó é o próprio processo. Usado para rotear (ou redirecionar)
+// requisições de chat de forma consistente, em vez de round-robin.
+func (c *Cluster) Owner(sessionID string) (addr string, isLocal bool) {
+	members := c.aliveMembers()
+	if len(members) == 0 {
+		return c.cfg.BindAddr, true
+	}
+
+	type ringEntry struct {
+		hash   uint32
+		member serf.Member
+	}
+
+	entries := make([]ringEntry, 0, len(members)*virtualNodesPerMember)
+	for _, m := range members {
+		for i := 0; i < virtualNodesPerMember; i++ {
+			entries = append(entries, ringEntry{hash: hashString(fmt.Sprintf("%s-%d", m.Name, i)), member: m})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].hash < entries[j].hash })
+
+	target := hashString(sessionID)
+	idx := sort.Search(len(entries), func(i int) bool { return entries[i].hash >= target })
+	if idx == len(entries) {
+		idx = 0
+	}
+
+	owner := entries[idx].member
+	return owner.Tags["rpc_addr"], owner.Name == c.cfg.NodeID
+}
+
+func (c *Cluster) aliveMembers() []serf.Member {
+	var alive []serf.Member
+	for _, m := range c.Serf.Members() {
+		if m.Status == serf.StatusAlive {
+			alive = append(alive, m)
+		}
+	}
+	return alive
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// Status resume o estado do cluster para o endpoint /cluster/status.
+type Status struct {
+	NodeID      string   `json:"node_id"`
+	Peers       []string `json:"peers"`
+	Leader      string   `json:"leader"`
+	CommitIndex uint64   `json:"commit_index"`
+	RaftState   string   `json:"raft_state"`
+}
+
+// Status retorna um retrato do estado atual do cluster.
+func (c *Cluster) Status() Status {
+	peers := make([]string, 0, len(c.aliveMembers()))
+	for _, m := range c.aliveMembers() {
+		peers = append(peers, m.Name)
+	}
+
+	return Status{
+		NodeID:      c.cfg.NodeID,
+		Peers:       peers,
+		Leader:      string(c.Raft.Leader()),
+		CommitIndex: c.Raft.CommitIndex(),
+		RaftState:   c.Raft.State().String(),
+	}
+}
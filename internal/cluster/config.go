@@ -0,0 +1,58 @@
+// Package cluster implementa um modo opcional de alta disponibilidade para o
+// servidor: os nós descobrem uns aos outros via gossip (Serf) e replicam o
+// histórico das ChatSession via Raft, para que as sessões sobrevivam à queda
+// de um nó e para que requisições cheguem de forma consistente ao dono de
+// cada sessão.
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vitormoschetta/go-adk/internal/config"
+)
+
+// Config descreve os parâmetros de um nó do cluster, lidos do ambiente.
+type Config struct {
+	NodeID    string
+	BindAddr  string // HTTP + Raft multiplexados via cmux (CLUSTER_BIND_ADDR)
+	SerfAddr  string // endereço de gossip do Serf (CLUSTER_SERF_ADDR)
+	RaftDir   string
+	Bootstrap bool
+	Join      []string
+}
+
+// Enabled é true quando o servidor deve rodar em modo cluster. NODE_ID é o
+// interruptor: sem ele, o servidor roda como instância única, exatamente
+// como antes deste pacote existir.
+func (c Config) Enabled() bool {
+	return c.NodeID != ""
+}
+
+// FromEnv lê NODE_ID, CLUSTER_BIND_ADDR, CLUSTER_SERF_ADDR, RAFT_DIR,
+// CLUSTER_BOOTSTRAP e CLUSTER_JOIN a partir de appCfg. Retorna a Config
+// zero-value (Enabled() == false) quando NODE_ID não está definido.
+func FromEnv(appCfg *config.Config) (Config, error) {
+	nodeID := appCfg.Get("NODE_ID")
+	if nodeID == "" {
+		return Config{}, nil
+	}
+
+	cfg := Config{
+		NodeID:    nodeID,
+		BindAddr:  appCfg.GetOrDefault("CLUSTER_BIND_ADDR", ":8080"),
+		SerfAddr:  appCfg.GetOrDefault("CLUSTER_SERF_ADDR", ":7946"),
+		RaftDir:   appCfg.GetOrDefault("RAFT_DIR", "raft-data"),
+		Bootstrap: appCfg.Get("CLUSTER_BOOTSTRAP") == "true",
+	}
+
+	if v := appCfg.Get("CLUSTER_JOIN"); v != "" {
+		cfg.Join = strings.Split(v, ",")
+	}
+
+	if cfg.RaftDir == "" {
+		return Config{}, fmt.Errorf("RAFT_DIR must not be empty")
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,134 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+
+	sessionstore "github.com/vitormoschetta/go-adk/internal/session"
+)
+
+// putCommand é o único tipo de entrada replicada no log do Raft: grava o
+// Record de uma sessão no Store local de cada nó, exatamente como
+// service.SessionManager.Persist faria fora de um cluster.
+type putCommand struct {
+	Key    string              `json:"key"`
+	Record sessionstore.Record `json:"record"`
+	TTL    time.Duration       `json:"ttl"`
+}
+
+// FSM aplica os comandos replicados pelo Raft ao Store local, para que o
+// histórico de cada ChatSession esteja disponível em qualquer nó do cluster.
+// Mantém o TTL com que cada chave foi gravada pela última vez, para que
+// Snapshot/Restore consigam preservá-lo - sem isso, um nó que recebesse um
+// snapshot (ao entrar no cluster ou ao ficar atrasado demais no log) gravaria
+// as sessões sem expiração, e o janitor de ociosidade de SessionManager nunca
+// conseguiria reclamá-las ali.
+type FSM struct {
+	store sessionstore.Store
+
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+}
+
+// NewFSM cria um FSM apoiado no Store informado.
+func NewFSM(store sessionstore.Store) *FSM {
+	return &FSM{store: store, ttls: make(map[string]time.Duration)}
+}
+
+// Apply decodifica e executa uma entrada do log do Raft.
+func (f *FSM) Apply(log *raft.Log) interface{} {
+	var cmd putCommand
+	if err := json.Unmarshal(log.Data, &cmd); err != nil {
+		return fmt.Errorf("cluster: failed to decode raft log entry: %w", err)
+	}
+
+	rec := cmd.Record
+	if err := f.store.Put(context.Background(), cmd.Key, &rec, cmd.TTL); err != nil {
+		return fmt.Errorf("cluster: failed to apply put command: %w", err)
+	}
+
+	f.mu.Lock()
+	f.ttls[cmd.Key] = cmd.TTL
+	f.mu.Unlock()
+
+	return nil
+}
+
+// snapshotRecord pareia um Record com o TTL com que foi gravado, para que
+// Restore não precise adivinhar um valor ao repor o Store de um nó.
+type snapshotRecord struct {
+	Record sessionstore.Record `json:"record"`
+	TTL    time.Duration       `json:"ttl"`
+}
+
+// fsmSnapshot carrega uma cópia ponto-no-tempo de todas as sessões
+// conhecidas, usada para permitir que o Raft compacte o log e traga nós
+// atrasados (ou novos) diretamente para o estado atual.
+type fsmSnapshot struct {
+	records []snapshotRecord
+}
+
+// Snapshot varre o Store local e empacota todos os registros atuais, junto
+// com o último TTL conhecido de cada um.
+func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
+	ctx := context.Background()
+
+	keys, err := f.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cluster: failed to list sessions for snapshot: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	records := make([]snapshotRecord, 0, len(keys))
+	for _, key := range keys {
+		rec, err := f.store.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		records = append(records, snapshotRecord{Record: *rec, TTL: f.ttls[key]})
+	}
+
+	return &fsmSnapshot{records: records}, nil
+}
+
+// Restore repõe o Store local a partir de um snapshot - chamado quando um nó
+// entra no cluster ou fica atrasado demais para aplicar o log incrementalmente.
+func (f *FSM) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	var records []snapshotRecord
+	if err := json.NewDecoder(rc).Decode(&records); err != nil {
+		return fmt.Errorf("cluster: failed to decode snapshot: %w", err)
+	}
+
+	ctx := context.Background()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, sr := range records {
+		rec := sr.Record
+		if err := f.store.Put(ctx, rec.SessionID, &rec, sr.TTL); err != nil {
+			return fmt.Errorf("cluster: failed to restore session %s: %w", rec.SessionID, err)
+		}
+		f.ttls[rec.SessionID] = sr.TTL
+	}
+	return nil
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s.records); err != nil {
+		sink.Cancel()
+		return fmt.Errorf("cluster: failed to write snapshot: %w", err)
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
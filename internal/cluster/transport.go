@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/soheilhy/cmux"
+)
+
+// raftProtoHeader é escrito no início de toda conexão de saída do Raft, para
+// que o cmux consiga distingui-la do tráfego HTTP no mesmo socket (o mesmo
+// princípio usado pelo cmux para TLS/HTTP, aplicado a um protocolo próprio).
+const raftProtoHeader = "raft-rpc\n"
+
+// raftStreamLayer implementa raft.StreamLayer sobre o net.Listener casado
+// pelo cmux para o prefixo raftProtoHeader, permitindo multiplexar o
+// transporte TCP do Raft e o servidor HTTP em uma única porta.
+type raftStreamLayer struct {
+	net.Listener
+}
+
+// Accept descarta o cabeçalho escrito por Dial antes de entregar a conexão
+// ao NetworkTransport do Raft, que não espera esses bytes extras.
+func (l *raftStreamLayer) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(raftProtoHeader))
+	if _, err := io.ReadFull(conn, header); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Dial abre uma conexão TCP simples para address e marca o tráfego como
+// Raft escrevendo raftProtoHeader antes de devolver a conexão, para que o
+// cmux do nó remoto a roteie para o raftStreamLayer em vez do HTTP.
+func (l *raftStreamLayer) Dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", string(address), timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte(raftProtoHeader)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// newRaftStreamLayer constrói o StreamLayer do Raft a partir do cmux
+// informado, registrando o matcher de prefixo antes de qualquer matcher
+// "pega-tudo" (a ordem de registro no cmux é a ordem de prioridade).
+func newRaftStreamLayer(mux cmux.CMux) *raftStreamLayer {
+	return &raftStreamLayer{Listener: mux.Match(cmux.PrefixMatcher(raftProtoHeader))}
+}
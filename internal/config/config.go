@@ -0,0 +1,115 @@
+// Package config carrega a configuração do servidor de forma em camadas -
+// .env.default (padrões versionados), .env (segredos locais) e, se
+// BASE_DIR estiver definido, ${BASE_DIR}/.env (overrides de implantação) -
+// e expõe acesso tipado a ela, incluindo feature flags que podem ser
+// recarregadas em tempo de execução via SIGHUP.
+package config
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// defaultEnvFile, localEnvFile e baseDirEnvVar descrevem, em ordem crescente
+// de prioridade, as três camadas de .env carregadas por Load: arquivos
+// carregados depois sobrescrevem chaves já carregadas por arquivos
+// anteriores. Variáveis já definidas no ambiente do processo têm sempre
+// prioridade máxima, para que implantações continuem podendo configurar o
+// servidor normalmente via variáveis de ambiente reais.
+const (
+	defaultEnvFile = ".env.default"
+	localEnvFile   = ".env"
+	baseDirEnvVar  = "BASE_DIR"
+)
+
+// Config dá acesso tipado às variáveis de configuração do servidor, lidas em
+// camadas pelo Load. É seguro chamar seus métodos concorrentemente,
+// inclusive durante um Reload.
+type Config struct {
+	mu   sync.RWMutex
+	vars map[string]string
+
+	Flags *FeatureFlags
+}
+
+// Load lê as camadas de .env (ver Config) e monta um Config pronto para uso.
+// A ausência de .env.default não é um erro - é o arquivo de padrões
+// versionados e pode simplesmente não existir em implantações que
+// configuram tudo via variáveis de ambiente reais.
+func Load() (*Config, error) {
+	c := &Config{}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	c.Flags = &FeatureFlags{cfg: c}
+	return c, nil
+}
+
+// Reload relê as três camadas de .env do disco e substitui os valores
+// atuais atomicamente, para refletir um SIGHUP sem invalidar o *Config já
+// injetado em handlers e no agente.
+func (c *Config) Reload() error {
+	return c.reload()
+}
+
+func (c *Config) reload() error {
+	vars := map[string]string{}
+
+	if fileVars, err := godotenv.Read(defaultEnvFile); err == nil {
+		mergeInto(vars, fileVars)
+	}
+
+	if fileVars, err := godotenv.Read(localEnvFile); err == nil {
+		mergeInto(vars, fileVars)
+	} else {
+		log.Println("Warning: .env file not found or could not be loaded")
+	}
+
+	if baseDir := os.Getenv(baseDirEnvVar); baseDir != "" {
+		if fileVars, err := godotenv.Read(filepath.Join(baseDir, ".env")); err == nil {
+			mergeInto(vars, fileVars)
+		} else {
+			log.Printf("Warning: %s/.env not found or could not be loaded", baseDir)
+		}
+	}
+
+	// O ambiente real do processo sempre vence - os arquivos acima só
+	// preenchem o que não tiver sido definido por fora.
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			vars[key] = value
+		}
+	}
+
+	c.mu.Lock()
+	c.vars = vars
+	c.mu.Unlock()
+	return nil
+}
+
+// Get retorna o valor de key segundo a configuração em camadas atual, ou ""
+// quando ausente.
+func (c *Config) Get(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.vars[key]
+}
+
+// GetOrDefault retorna o valor de key, ou def quando ausente ou vazio.
+func (c *Config) GetOrDefault(key, def string) string {
+	if v := c.Get(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func mergeInto(dst, src map[string]string) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
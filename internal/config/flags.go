@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// featureEnvPrefix é o prefixo das variáveis de ambiente lidas por
+// FeatureFlags - ex: FEATURE_ENABLE_STREAMING, FEATURE_ENABLE_TOOL_X.
+const featureEnvPrefix = "FEATURE_"
+
+// FeatureFlags dá acesso tipado às flags FEATURE_* da Config que o gerou.
+// Reflete um Reload automaticamente, já que lê direto do Config subjacente.
+type FeatureFlags struct {
+	cfg *Config
+}
+
+// IsEnabled reporta se a flag name (ex: "ENABLE_STREAMING") está ligada,
+// checando FEATURE_<name>. Valores reconhecidos como "ligado" seguem
+// strconv.ParseBool; qualquer outra coisa (incluindo ausência) é false.
+func (f *FeatureFlags) IsEnabled(name string) bool {
+	v := f.cfg.Get(envKey(name))
+	enabled, err := strconv.ParseBool(v)
+	return err == nil && enabled
+}
+
+// Int retorna o valor inteiro da flag name, ou def quando ausente ou não
+// numérico.
+func (f *FeatureFlags) Int(name string, def int) int {
+	v := f.cfg.Get(envKey(name))
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+// All varre a configuração atual e retorna toda flag FEATURE_* presente,
+// pelo nome sem o prefixo - usado por GET /config/features para os
+// operadores conferirem o que está valendo em produção.
+func (f *FeatureFlags) All() map[string]string {
+	f.cfg.mu.RLock()
+	defer f.cfg.mu.RUnlock()
+
+	flags := make(map[string]string)
+	for k, v := range f.cfg.vars {
+		if name, ok := strings.CutPrefix(k, featureEnvPrefix); ok {
+			flags[name] = v
+		}
+	}
+	return flags
+}
+
+func envKey(name string) string {
+	return featureEnvPrefix + strings.ToUpper(name)
+}
@@ -3,28 +3,49 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/go-chi/chi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/genai"
 
+	"github.com/vitormoschetta/go-adk/internal/mcpx"
 	"github.com/vitormoschetta/go-adk/internal/model"
 	"github.com/vitormoschetta/go-adk/internal/server"
+	"github.com/vitormoschetta/go-adk/internal/service"
+	sessionstore "github.com/vitormoschetta/go-adk/internal/session"
 )
 
+// toolsCacheEntry guarda a última lista de ferramentas MCP obtida para um
+// agente e o número de reconexões do seu transporte no momento em que ela
+// foi obtida, para saber quando ela precisa ser refeita.
+type toolsCacheEntry struct {
+	tools      []*mcp.Tool
+	reconnects int64
+}
+
 // Handler contém as dependências necessárias para os handlers HTTP
 type Handler struct {
 	server *server.Server
+
+	toolsMu    sync.Mutex
+	toolsCache map[string]*toolsCacheEntry
 }
 
 // NewHandler cria uma nova instância do Handler
 func NewHandler(srv *server.Server) *Handler {
 	return &Handler{
-		server: srv,
+		server:     srv,
+		toolsCache: make(map[string]*toolsCacheEntry),
 	}
 }
 
@@ -43,45 +64,289 @@ func (h *Handler) HandleRoot(w http.ResponseWriter, r *http.Request) {
 				"example": map[string]string{
 					"message":    "Hello, how can you help me?",
 					"session_id": "optional-session-id",
+					"agent":      "optional-agent-name",
+				},
+			},
+			"chat_stream": map[string]interface{}{
+				"url":         "http://localhost:8080/api/chat/stream",
+				"method":      "POST",
+				"description": "Send a message to the agent and receive the response as Server-Sent Events",
+				"example": map[string]string{
+					"message":    "Hello, how can you help me?",
+					"session_id": "optional-session-id",
+					"agent":      "optional-agent-name",
 				},
 			},
+			"agents": map[string]interface{}{
+				"url":         "http://localhost:8080/api/agents",
+				"method":      "GET",
+				"description": "List registered agents; tool_count is the number of MCP toolsets configured for each agent, not the number of tools",
+			},
+			"chat_stream_sse": map[string]interface{}{
+				"url":         "http://localhost:8080/chat/stream",
+				"method":      "POST",
+				"description": "Like /api/chat/stream, with periodic heartbeats for idle-connection-closing proxies",
+			},
+			"chat_stream_ws": map[string]interface{}{
+				"url":         "ws://localhost:8080/chat/ws",
+				"method":      "GET (Upgrade: websocket)",
+				"description": "Bidirectional streaming chat: send {\"type\":\"cancel\"} to abort a turn mid-generation",
+			},
 			"health": map[string]interface{}{
 				"url":         "http://localhost:8080/health",
 				"method":      "GET",
 				"description": "Health check endpoint",
 			},
 			"tools": map[string]interface{}{
-				"url":         "http://localhost:8080/api/tools",
+				"url":         "http://localhost:8080/api/tools?agent=optional-agent-name",
 				"method":      "GET",
-				"description": "List available MCP tools",
+				"description": "List available MCP tools for an agent (defaults to the default agent)",
+			},
+			"pow_challenge": map[string]interface{}{
+				"url":         "http://localhost:8080/api/pow/challenge",
+				"method":      "GET",
+				"description": "Get a proof-of-work challenge required by X-Pow-Seed/X-Pow-Nonce headers on /api/chat",
+			},
+			"delete_session": map[string]interface{}{
+				"url":         "http://localhost:8080/api/sessions/{id}",
+				"method":      "DELETE",
+				"description": "Delete a session's persisted history",
+			},
+			"metrics": map[string]interface{}{
+				"url":         "http://localhost:8080/metrics",
+				"method":      "GET",
+				"description": "Prometheus-style metrics, including MCP transport reconnects",
 			},
-		},
-		"agent": map[string]string{
-			"name":        "helper_agent",
-			"description": "Helper agent with MCP tools",
 		},
 	}
 
 	json.NewEncoder(w).Encode(response)
 }
 
-// HandleHealth retorna o status de saúde do servidor
+// HandleHealth retorna o status de saúde do servidor. Responde 503 com um
+// corpo JSON listando os agentes cuja conexão MCP está fora do ar, para que
+// um orquestrador (k8s, load balancer) tire a instância de rotação.
 func (h *Handler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	degraded := map[string]string{}
+	for name, transport := range h.server.McpTransports {
+		if !transport.Ready() {
+			degraded[name] = fmt.Sprintf("%v", transport.LastError())
+		}
+	}
+
+	if len(degraded) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     "degraded",
+			"mcp_errors": degraded,
+		})
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
+}
+
+// HandleAgents lista os agentes registrados, para que clientes descubram o
+// que podem passar no campo "agent" de /api/chat.
+func (h *Handler) HandleAgents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"default_agent": h.server.Registry.DefaultName(),
+		"agents":        h.server.Registry.List(),
+	})
 }
 
-// HandleTools retorna informações sobre as ferramentas MCP disponíveis
+// HandleFeatures expõe GET /config/features: os valores atuais de toda
+// feature flag FEATURE_* configurada, para que operadores confiram o que
+// está valendo sem precisar inspecionar o ambiente do processo. Reflete um
+// SIGHUP (ver cmd/main.go) porque lê direto da Config compartilhada.
+func (h *Handler) HandleFeatures(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.server.Cfg.Flags.All())
+}
+
+// HandleClusterStatus expõe o estado do cluster (peers, líder, índice de
+// commit do Raft) quando o servidor roda em modo cluster (ver
+// internal/cluster). Fora desse modo, informa que o cluster está desabilitado.
+func (h *Handler) HandleClusterStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if h.server.Cluster == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false})
+		return
+	}
+
+	json.NewEncoder(w).Encode(h.server.Cluster.Status())
+}
+
+// redirectIfNotOwner checa, em modo cluster, se este nó é o dono da sessão
+// segundo o anel de hash consistente; se não for, responde com um redirect
+// 307 para o nó dono e retorna true para que o chamador pare de processar a
+// requisição. Fora do modo cluster (ou para uma sessão nova, sem ID ainda),
+// não faz nada.
+func (h *Handler) redirectIfNotOwner(w http.ResponseWriter, r *http.Request, sessionID string) bool {
+	if h.server.Cluster == nil || sessionID == "" {
+		return false
+	}
+
+	addr, isLocal := h.server.Cluster.Owner(sessionID)
+	if isLocal {
+		return false
+	}
+
+	target := "http://" + addr + r.URL.Path
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusTemporaryRedirect)
+	return true
+}
+
+// HandleTools enumera as ferramentas MCP disponíveis para o agente pedido
+// via ?agent= (o padrão do servidor quando ausente), chamando o
+// McpSession.ListTools diretamente. A lista é cacheada por agente e só é
+// refeita quando o McpTransport correspondente reconectou desde a última
+// chamada.
 func (h *Handler) HandleTools(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	response := map[string]interface{}{
-		"message":      "MCP tools are available through the agent",
-		"note":         "To see available tools, ask the agent 'What tools do you have available?' in a chat message",
-		"mcp_endpoint": h.server.McpEndpoint,
+	agentName := r.URL.Query().Get("agent")
+	if agentName == "" {
+		agentName = h.server.Registry.DefaultName()
 	}
 
-	json.NewEncoder(w).Encode(response)
+	mcpSession, ok := h.server.McpSessions[agentName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("agent %q has no MCP connection", agentName), http.StatusBadRequest)
+		return
+	}
+
+	tools, err := h.listTools(r.Context(), agentName, mcpSession, h.server.McpTransports[agentName])
+	if err != nil {
+		log.Printf("Error listing MCP tools for agent %q: %v", agentName, err)
+		http.Error(w, "Failed to list MCP tools", http.StatusBadGateway)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent":        agentName,
+		"mcp_endpoint": h.server.McpEndpoints[agentName],
+		"tools":        tools,
+	})
+}
+
+// listTools retorna a lista cacheada de ferramentas MCP do agente, repopulando
+// o cache quando ele está vazio ou quando o transporte reconectou desde o
+// último preenchimento.
+func (h *Handler) listTools(ctx context.Context, agentName string, mcpSession *mcp.ClientSession, mcpTransport *mcpx.ResilientTransport) ([]*mcp.Tool, error) {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+
+	reconnects := mcpTransport.Reconnects()
+	if entry, ok := h.toolsCache[agentName]; ok && entry.reconnects == reconnects {
+		return entry.tools, nil
+	}
+
+	result, err := mcpSession.ListTools(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h.toolsCache[agentName] = &toolsCacheEntry{tools: result.Tools, reconnects: reconnects}
+	return result.Tools, nil
+}
+
+// HandleMetrics expõe métricas em formato de exposição do Prometheus: o
+// contador de reconexões de cada transporte MCP, por agente.
+func (h *Handler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintf(w, "# HELP mcpx_reconnects_total Total number of MCP transport reconnects since process start, by agent.\n")
+	fmt.Fprintf(w, "# TYPE mcpx_reconnects_total counter\n")
+	for name, transport := range h.server.McpTransports {
+		fmt.Fprintf(w, "mcpx_reconnects_total{agent=%q} %d\n", name, transport.Reconnects())
+	}
+}
+
+// HandlePowChallenge emite um novo desafio de proof-of-work a ser resolvido
+// antes de chamar os endpoints de chat.
+func (h *Handler) HandlePowChallenge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	challenge, err := h.server.PowManager.NewChallenge()
+	if err != nil {
+		log.Printf("Error creating pow challenge: %v", err)
+		http.Error(w, "Failed to create challenge", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(challenge)
+}
+
+// HandleDeleteSession remove a sessão do cache local e do Store de
+// persistência, liberando seu histórico imediatamente em vez de esperar o
+// TTL expirar.
+func (h *Handler) HandleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		http.Error(w, "session id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.server.SessionManager.Delete(r.Context(), sessionID); err != nil {
+		log.Printf("Error deleting session %s: %v", sessionID, err)
+		http.Error(w, "Failed to delete session", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resolveAgent escolhe o agente e o runner a usar para a requisição: o
+// informado em req.Agent, caindo para o agente padrão do Registry quando
+// vazio.
+func (h *Handler) resolveAgent(requested string) (string, agent.Agent, *runner.Runner, error) {
+	name := requested
+	if name == "" {
+		name = h.server.Registry.DefaultName()
+	}
+
+	a, err := h.server.Registry.Get(name)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	agentRunner, ok := h.server.Runners[name]
+	if !ok {
+		return "", nil, nil, fmt.Errorf("no runner registered for agent %q", name)
+	}
+
+	return name, a, agentRunner, nil
+}
+
+// persistSession grava o histórico da sessão: via Raft (replicado para todo
+// o cluster) quando o servidor roda em modo cluster, ou diretamente no Store
+// local caso contrário.
+func (h *Handler) persistSession(ctx context.Context, cs *service.ChatSession) error {
+	if h.server.Cluster == nil {
+		return h.server.SessionManager.Persist(ctx, cs)
+	}
+
+	data, err := json.Marshal(cs.History)
+	if err != nil {
+		return err
+	}
+
+	return h.server.Cluster.ApplyPut(cs.ID, &sessionstore.Record{
+		SessionID: cs.ID,
+		UserID:    cs.UserID,
+		History:   data,
+		UpdatedAt: time.Now(),
+	}, h.server.SessionManager.TTL())
 }
 
 // HandleChat processa mensagens enviadas ao agente
@@ -106,20 +371,46 @@ func (h *Handler) HandleChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Obter ou criar sessão HTTP (para tracking local)
-	chatSess := h.server.SessionManager.GetOrCreate(req.SessionID, h.server.Agent)
+	if h.redirectIfNotOwner(w, r, req.SessionID) {
+		return
+	}
+
+	agentName, a, agentRunner, err := h.resolveAgent(req.Agent)
+	if err != nil {
+		json.NewEncoder(w).Encode(model.ChatResponse{
+			Error: fmt.Sprintf("Unknown agent %q", req.Agent),
+		})
+		return
+	}
+
+	// Criar contexto de execução
+	execCtx := context.Background()
+
+	userID := h.server.IdentityCalculator.Calculate(r)
+
+	// Obter ou criar sessão HTTP (reidratando histórico persistido, se houver)
+	sameUser := func(storedUserID string) bool { return h.server.IdentityCalculator.Matches(storedUserID, r) }
+	chatSess, err := h.server.SessionManager.GetOrCreate(execCtx, req.SessionID, userID, sameUser, a)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionUserMismatch) {
+			http.Error(w, "session id belongs to a different user", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error getting or creating session: %v", err)
+		json.NewEncoder(w).Encode(model.ChatResponse{
+			Error: "Failed to get or create session",
+		})
+		return
+	}
 
 	chatSess.Mu.Lock()
 	defer chatSess.Mu.Unlock()
 
 	// Executar o agente com a mensagem usando o runner
-	log.Printf("Processing message in session %s: %s", chatSess.ID, req.Message)
-
-	// Criar contexto de execução
-	execCtx := context.Background()
+	log.Printf("Processing message in session %s with agent %q: %s", chatSess.ID, agentName, req.Message)
 
 	// Verificar se a sessão existe no SessionService do ADK, se não criar
-	_, err := h.server.SessionService.Get(execCtx, &session.GetRequest{
+	_, err = h.server.SessionService.Get(execCtx, &session.GetRequest{
 		AppName:   "go-adk-http-server",
 		SessionID: chatSess.ID,
 	})
@@ -128,7 +419,7 @@ func (h *Handler) HandleChat(w http.ResponseWriter, r *http.Request) {
 		_, createErr := h.server.SessionService.Create(execCtx, &session.CreateRequest{
 			AppName:   "go-adk-http-server",
 			SessionID: chatSess.ID,
-			UserID:    "default-user",
+			UserID:    userID,
 		})
 		if createErr != nil && !strings.Contains(createErr.Error(), "already exists") {
 			// Erro real (não é "já existe")
@@ -154,10 +445,10 @@ func (h *Handler) HandleChat(w http.ResponseWriter, r *http.Request) {
 	var lastError error
 
 	// O runner.Run executa o agente e retorna eventos de sessão
-	for event, err := range h.server.AgentRunner.Run(execCtx, "default-user", chatSess.ID, userContent, agent.RunConfig{}) {
-		if err != nil {
-			lastError = err
-			log.Printf("Error running agent: %v", err)
+	for event, runErr := range agentRunner.Run(execCtx, userID, chatSess.ID, userContent, agent.RunConfig{}) {
+		if runErr != nil {
+			lastError = runErr
+			log.Printf("Error running agent: %v", runErr)
 			break
 		}
 
@@ -188,9 +479,170 @@ func (h *Handler) HandleChat(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Agent response in session %s: %s", chatSess.ID, responseStr)
 
+	chatSess.History = append(chatSess.History, userContent, &genai.Content{
+		Role:  "model",
+		Parts: []*genai.Part{{Text: responseStr}},
+	})
+	if err := h.persistSession(execCtx, chatSess); err != nil {
+		log.Printf("Error persisting session %s: %v", chatSess.ID, err)
+	}
+
 	// Retornar a resposta
 	json.NewEncoder(w).Encode(model.ChatResponse{
 		Response:  responseStr,
 		SessionID: chatSess.ID,
 	})
 }
+
+// HandleChatStream processa mensagens enviadas ao agente e transmite a
+// resposta via Server-Sent Events, emitindo um frame por evento do runner
+// em vez de esperar o turno inteiro terminar.
+func (h *Handler) HandleChatStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var req model.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing JSON: %v", err)
+		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Message == "" {
+		http.Error(w, "Message is required", http.StatusBadRequest)
+		return
+	}
+
+	if h.redirectIfNotOwner(w, r, req.SessionID) {
+		return
+	}
+
+	agentName, a, agentRunner, err := h.resolveAgent(req.Agent)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Unknown agent %q", req.Agent), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Contexto da requisição: cancela automaticamente se o cliente
+	// desconectar, abortando tool calls em andamento no runner.
+	ctx := r.Context()
+
+	userID := h.server.IdentityCalculator.Calculate(r)
+
+	sameUser := func(storedUserID string) bool { return h.server.IdentityCalculator.Matches(storedUserID, r) }
+	chatSess, err := h.server.SessionManager.GetOrCreate(ctx, req.SessionID, userID, sameUser, a)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionUserMismatch) {
+			http.Error(w, "session id belongs to a different user", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error getting or creating session: %v", err)
+		http.Error(w, "Failed to get or create session", http.StatusInternalServerError)
+		return
+	}
+
+	chatSess.Mu.Lock()
+	defer chatSess.Mu.Unlock()
+
+	log.Printf("Streaming message in session %s with agent %q: %s", chatSess.ID, agentName, req.Message)
+
+	_, err = h.server.SessionService.Get(ctx, &session.GetRequest{
+		AppName:   "go-adk-http-server",
+		SessionID: chatSess.ID,
+	})
+	if err != nil {
+		_, createErr := h.server.SessionService.Create(ctx, &session.CreateRequest{
+			AppName:   "go-adk-http-server",
+			SessionID: chatSess.ID,
+			UserID:    userID,
+		})
+		if createErr != nil && !strings.Contains(createErr.Error(), "already exists") {
+			log.Printf("Error creating session in service: %v", createErr)
+			writeSSE(w, flusher, "error", model.StreamEvent{
+				SessionID: chatSess.ID,
+				Error:     fmt.Sprintf("Failed to create session: %v", createErr),
+			})
+			return
+		}
+	}
+
+	userContent := &genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{
+			{Text: req.Message},
+		},
+	}
+
+	var responseText strings.Builder
+
+	for event, runErr := range agentRunner.Run(ctx, userID, chatSess.ID, userContent, agent.RunConfig{}) {
+		if ctx.Err() != nil {
+			log.Printf("Client disconnected from stream in session %s", chatSess.ID)
+			return
+		}
+
+		if runErr != nil {
+			log.Printf("Error running agent: %v", runErr)
+			writeSSE(w, flusher, "error", model.StreamEvent{
+				SessionID: chatSess.ID,
+				Error:     runErr.Error(),
+			})
+			return
+		}
+
+		if event == nil || event.Content == nil {
+			continue
+		}
+
+		for _, part := range event.Content.Parts {
+			switch {
+			case part.Text != "":
+				responseText.WriteString(part.Text)
+				writeSSE(w, flusher, "token", model.StreamEvent{
+					SessionID: chatSess.ID,
+					Delta:     part.Text,
+				})
+			case part.FunctionCall != nil:
+				writeSSE(w, flusher, "tool_call", model.StreamEvent{
+					SessionID:    chatSess.ID,
+					FunctionCall: part.FunctionCall,
+				})
+			case part.FunctionResponse != nil:
+				writeSSE(w, flusher, "tool_result", model.StreamEvent{
+					SessionID:        chatSess.ID,
+					FunctionResponse: part.FunctionResponse,
+				})
+			}
+		}
+	}
+
+	chatSess.History = append(chatSess.History, userContent, &genai.Content{
+		Role:  "model",
+		Parts: []*genai.Part{{Text: responseText.String()}},
+	})
+	if err := h.persistSession(ctx, chatSess); err != nil {
+		log.Printf("Error persisting session %s: %v", chatSess.ID, err)
+	}
+
+	writeSSE(w, flusher, "done", model.StreamEvent{SessionID: chatSess.ID})
+}
+
+// writeSSE serializa payload como JSON e escreve um frame SSE completo,
+// descarregando o buffer imediatamente para que o cliente o veja sem atraso.
+func writeSSE(w http.ResponseWriter, flusher http.Flusher, event string, payload model.StreamEvent) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling SSE payload: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	flusher.Flush()
+}
@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"github.com/vitormoschetta/go-adk/internal/model"
+	"github.com/vitormoschetta/go-adk/internal/service"
+)
+
+// heartbeatInterval é o intervalo entre pings/comentários mandados para
+// manter proxies intermediários de fechar conexões SSE/WS ociosas.
+const heartbeatInterval = 15 * time.Second
+
+// streamFrame é um evento produzido por um turno em andamento: o nome do
+// evento (usado como `event:` no SSE e como campo "event" no JSON do WS) e o
+// payload correspondente.
+type streamFrame struct {
+	Event string
+	Data  model.StreamEvent
+}
+
+// frameBuffer entrega os streamFrame de um turno a um único consumidor,
+// coalescendo frames "token" consecutivos quando o consumidor está mais
+// lento que o produtor, em vez de bloquear o turno ou derrubar a conexão.
+type frameBuffer struct {
+	mu     sync.Mutex
+	frames []streamFrame
+	signal chan struct{}
+	done   bool
+}
+
+func newFrameBuffer() *frameBuffer {
+	return &frameBuffer{signal: make(chan struct{}, 1)}
+}
+
+func (b *frameBuffer) push(f streamFrame) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if f.Event == "token" && len(b.frames) > 0 {
+		if last := &b.frames[len(b.frames)-1]; last.Event == "token" {
+			last.Data.Delta += f.Data.Delta
+			b.notifyLocked()
+			return
+		}
+	}
+	b.frames = append(b.frames, f)
+	b.notifyLocked()
+}
+
+func (b *frameBuffer) close() {
+	b.mu.Lock()
+	b.done = true
+	b.notifyLocked()
+	b.mu.Unlock()
+}
+
+func (b *frameBuffer) notifyLocked() {
+	select {
+	case b.signal <- struct{}{}:
+	default:
+	}
+}
+
+// drain retorna os frames pendentes (esvaziando a fila) e se o produtor já
+// terminou o turno.
+func (b *frameBuffer) drain() ([]streamFrame, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	frames := b.frames
+	b.frames = nil
+	return frames, b.done
+}
+
+// pumpFrames drena buf até o turno terminar ou ctx ser cancelado, chamando
+// emit para cada frame e heartbeat quando nenhum frame chega dentro de
+// heartbeatInterval.
+func pumpFrames(ctx context.Context, buf *frameBuffer, emit func(streamFrame), heartbeat func()) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-buf.signal:
+			frames, done := buf.drain()
+			for _, f := range frames {
+				emit(f)
+			}
+			if len(frames) > 0 {
+				ticker.Reset(heartbeatInterval)
+			}
+			if done {
+				return
+			}
+		case <-ticker.C:
+			heartbeat()
+		}
+	}
+}
+
+// ensureADKSession garante que a sessão exista no SessionService do ADK,
+// criando-a se necessário (idempotente: tolera "already exists").
+func (h *Handler) ensureADKSession(ctx context.Context, sessionID, userID string) error {
+	_, err := h.server.SessionService.Get(ctx, &session.GetRequest{
+		AppName:   "go-adk-http-server",
+		SessionID: sessionID,
+	})
+	if err == nil {
+		return nil
+	}
+
+	_, err = h.server.SessionService.Create(ctx, &session.CreateRequest{
+		AppName:   "go-adk-http-server",
+		SessionID: sessionID,
+		UserID:    userID,
+	})
+	if err != nil && !strings.Contains(err.Error(), "already exists") {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// startTurn resolve o agente/sessão pedidos e dispara a execução do turno em
+// segundo plano, publicando cada evento no frameBuffer retornado conforme
+// são produzidos. O cancel retornado interrompe o turno em andamento (usado
+// pelo frame "cancel" do WebSocket). O Mu da sessão é travado antes de a
+// goroutine do turno iniciar e só é liberado quando ela termina, serializando
+// turnos da mesma sessão sem bloquear outras sessões.
+func (h *Handler) startTurn(ctx context.Context, r *http.Request, req model.ChatRequest, userID string) (*service.ChatSession, *frameBuffer, context.CancelFunc, error) {
+	_, a, agentRunner, err := h.resolveAgent(req.Agent)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	sameUser := func(storedUserID string) bool { return h.server.IdentityCalculator.Matches(storedUserID, r) }
+	chatSess, err := h.server.SessionManager.GetOrCreate(ctx, req.SessionID, userID, sameUser, a)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := h.ensureADKSession(ctx, chatSess.ID, userID); err != nil {
+		return nil, nil, nil, err
+	}
+
+	userContent := &genai.Content{
+		Role:  "user",
+		Parts: []*genai.Part{{Text: req.Message}},
+	}
+
+	turnCtx, cancel := context.WithCancel(context.Background())
+	buf := newFrameBuffer()
+
+	chatSess.Mu.Lock()
+	go func() {
+		defer chatSess.Mu.Unlock()
+		defer buf.close()
+
+		var responseText strings.Builder
+
+		for event, runErr := range agentRunner.Run(turnCtx, userID, chatSess.ID, userContent, agent.RunConfig{}) {
+			if runErr != nil {
+				if turnCtx.Err() != nil {
+					buf.push(streamFrame{Event: "cancelled", Data: model.StreamEvent{SessionID: chatSess.ID}})
+					return
+				}
+				log.Printf("Error running agent in session %s: %v", chatSess.ID, runErr)
+				buf.push(streamFrame{Event: "error", Data: model.StreamEvent{SessionID: chatSess.ID, Error: runErr.Error()}})
+				return
+			}
+
+			if event == nil || event.Content == nil {
+				continue
+			}
+
+			for _, part := range event.Content.Parts {
+				switch {
+				case part.Text != "":
+					responseText.WriteString(part.Text)
+					buf.push(streamFrame{Event: "token", Data: model.StreamEvent{SessionID: chatSess.ID, Delta: part.Text}})
+				case part.FunctionCall != nil:
+					buf.push(streamFrame{Event: "tool_call", Data: model.StreamEvent{SessionID: chatSess.ID, FunctionCall: part.FunctionCall}})
+				case part.FunctionResponse != nil:
+					buf.push(streamFrame{Event: "tool_result", Data: model.StreamEvent{SessionID: chatSess.ID, FunctionResponse: part.FunctionResponse}})
+				}
+			}
+		}
+
+		chatSess.History = append(chatSess.History, userContent, &genai.Content{
+			Role:  "model",
+			Parts: []*genai.Part{{Text: responseText.String()}},
+		})
+		if err := h.persistSession(context.Background(), chatSess); err != nil {
+			log.Printf("Error persisting session %s: %v", chatSess.ID, err)
+		}
+
+		buf.push(streamFrame{Event: "done", Data: model.StreamEvent{SessionID: chatSess.ID}})
+	}()
+
+	return chatSess, buf, cancel, nil
+}
+
+// HandleChatStreamSSE expõe POST /chat/stream: equivalente a
+// HandleChatStream, mas apoiado no frameBuffer compartilhado com
+// HandleChatStreamWS, incluindo heartbeats para manter proxies de não
+// fecharem a conexão enquanto o agente ainda está "pensando".
+func (h *Handler) HandleChatStreamSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var req model.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing JSON: %v", err)
+		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Message == "" {
+		http.Error(w, "Message is required", http.StatusBadRequest)
+		return
+	}
+
+	if h.redirectIfNotOwner(w, r, req.SessionID) {
+		return
+	}
+
+	userID := h.server.IdentityCalculator.Calculate(r)
+
+	chatSess, buf, cancel, err := h.startTurn(r.Context(), r, req, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionUserMismatch) {
+			http.Error(w, "session id belongs to a different user", http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer cancel()
+
+	// O turno roda em segundo plano com seu próprio turnCtx (ver startTurn),
+	// então precisa ser avisado explicitamente quando o cliente SSE
+	// desconecta - sem isso, o turno (e o Mu da sessão) ficaria preso até o
+	// agente terminar sozinho, mesmo sem mais ninguém para entregar o resultado.
+	go func() {
+		<-r.Context().Done()
+		cancel()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	log.Printf("Streaming message (SSE /chat/stream) in session %s", chatSess.ID)
+
+	pumpFrames(r.Context(), buf,
+		func(f streamFrame) { writeSSE(w, flusher, f.Event, f.Data) },
+		func() {
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		},
+	)
+}
+
+// wsUpgrader faz o upgrade HTTP -> WebSocket para HandleChatStreamWS. O
+// endpoint já fica atrás do PowManager.Middleware (ver SetupRouter), então
+// CheckOrigin não precisa repetir essa defesa aqui.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsIncoming é o formato dos frames que o cliente pode mandar depois do
+// ChatRequest inicial - hoje só "cancel", para interromper o turno em
+// andamento.
+type wsIncoming struct {
+	Type string `json:"type"`
+}
+
+// wsFrame é o formato dos frames que o servidor manda de volta: o mesmo
+// model.StreamEvent do SSE, com o nome do evento embutido.
+type wsFrame struct {
+	Event string `json:"event"`
+	model.StreamEvent
+}
+
+// HandleChatStreamWS expõe GET /chat/ws: a contraparte bidirecional de
+// HandleChatStreamSSE. O cliente manda um ChatRequest como primeiro frame de
+// texto e pode, a qualquer momento, mandar {"type":"cancel"} para abortar o
+// turno em andamento.
+func (h *Handler) HandleChatStreamWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	var req model.ChatRequest
+	if err := conn.ReadJSON(&req); err != nil {
+		conn.WriteJSON(wsFrame{Event: "error", StreamEvent: model.StreamEvent{Error: "invalid chat request"}})
+		return
+	}
+
+	if req.Message == "" {
+		conn.WriteJSON(wsFrame{Event: "error", StreamEvent: model.StreamEvent{Error: "message is required"}})
+		return
+	}
+
+	if req.SessionID != "" && h.server.Cluster != nil {
+		if addr, isLocal := h.server.Cluster.Owner(req.SessionID); !isLocal {
+			conn.WriteJSON(wsFrame{Event: "redirect", StreamEvent: model.StreamEvent{SessionID: req.SessionID, RedirectTo: addr}})
+			return
+		}
+	}
+
+	userID := h.server.IdentityCalculator.Calculate(r)
+
+	chatSess, buf, cancel, err := h.startTurn(r.Context(), r, req, userID)
+	if err != nil {
+		conn.WriteJSON(wsFrame{Event: "error", StreamEvent: model.StreamEvent{Error: err.Error()}})
+		return
+	}
+	defer cancel()
+
+	log.Printf("Streaming message (WS /chat/ws) in session %s", chatSess.ID)
+
+	// Lê frames do cliente em segundo plano: hoje só reage a "cancel", mas a
+	// leitura também detecta quando o cliente desconecta (ReadJSON retorna
+	// erro), liberando a goroutine do turno via cancel().
+	go func() {
+		for {
+			var in wsIncoming
+			if err := conn.ReadJSON(&in); err != nil {
+				cancel()
+				return
+			}
+			if in.Type == "cancel" {
+				cancel()
+			}
+		}
+	}()
+
+	pumpFrames(r.Context(), buf,
+		func(f streamFrame) {
+			if err := conn.WriteJSON(wsFrame{Event: f.Event, StreamEvent: f.Data}); err != nil {
+				log.Printf("WebSocket write error in session %s: %v", chatSess.ID, err)
+			}
+		},
+		func() { _ = conn.WriteMessage(websocket.PingMessage, nil) },
+	)
+}
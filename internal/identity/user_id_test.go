@@ -0,0 +1,109 @@
+package identity
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newRequest(t *testing.T, remoteAddr, userAgent, authHeader string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "/api/chat", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("User-Agent", userAgent)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	return req
+}
+
+func TestCalculate_IPDerivation(t *testing.T) {
+	calc := NewUserIDCalculator([]byte("server-secret"))
+
+	a := newRequest(t, "1.2.3.4:5555", "curl/8.0", "")
+	b := newRequest(t, "1.2.3.4:5555", "curl/8.0", "")
+	c := newRequest(t, "9.9.9.9:1111", "curl/8.0", "")
+
+	idA := calc.Calculate(a)
+	idB := calc.Calculate(b)
+	idC := calc.Calculate(c)
+
+	if idA != idB {
+		t.Errorf("expected identical IP+UA to derive the same user ID, got %q vs %q", idA, idB)
+	}
+	if idA == idC {
+		t.Errorf("expected different IPs to derive different user IDs, both got %q", idA)
+	}
+}
+
+func TestCalculate_BearerTokenDerivation(t *testing.T) {
+	calc := NewUserIDCalculator([]byte("server-secret"))
+
+	withToken := newRequest(t, "1.2.3.4:5555", "curl/8.0", "Bearer token-123")
+	sameToken := newRequest(t, "5.6.7.8:9999", "different-agent", "Bearer token-123")
+	otherToken := newRequest(t, "1.2.3.4:5555", "curl/8.0", "Bearer token-456")
+	noToken := newRequest(t, "1.2.3.4:5555", "curl/8.0", "")
+
+	idWithToken := calc.Calculate(withToken)
+	idSameToken := calc.Calculate(sameToken)
+	idOtherToken := calc.Calculate(otherToken)
+	idNoToken := calc.Calculate(noToken)
+
+	if idWithToken != idSameToken {
+		t.Errorf("expected the same bearer token to derive the same user ID regardless of IP/UA, got %q vs %q", idWithToken, idSameToken)
+	}
+	if idWithToken == idOtherToken {
+		t.Errorf("expected different bearer tokens to derive different user IDs, both got %q", idWithToken)
+	}
+	if idWithToken == idNoToken {
+		t.Errorf("expected bearer token derivation to differ from IP-based derivation, both got %q", idWithToken)
+	}
+}
+
+func TestCalculate_SaltRotation(t *testing.T) {
+	day1 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	calcDay1 := NewUserIDCalculatorWithClock([]byte("server-secret"), func() time.Time { return day1 })
+	calcDay2 := NewUserIDCalculatorWithClock([]byte("server-secret"), func() time.Time { return day2 })
+
+	req := newRequest(t, "1.2.3.4:5555", "curl/8.0", "")
+
+	idDay1 := calcDay1.Calculate(req)
+	idDay1Again := calcDay1.Calculate(req)
+	idDay2 := calcDay2.Calculate(req)
+
+	if idDay1 != idDay1Again {
+		t.Errorf("expected stable user ID within the same day, got %q vs %q", idDay1, idDay1Again)
+	}
+	if idDay1 == idDay2 {
+		t.Errorf("expected the daily salt rotation to change the user ID across days, both got %q", idDay1)
+	}
+}
+
+func TestMatches_ToleratesUTCMidnightRollover(t *testing.T) {
+	day1 := time.Date(2026, 1, 1, 23, 59, 0, 0, time.UTC)
+	day2 := time.Date(2026, 1, 2, 0, 1, 0, 0, time.UTC)
+
+	calcDay1 := NewUserIDCalculatorWithClock([]byte("server-secret"), func() time.Time { return day1 })
+	calcDay2 := NewUserIDCalculatorWithClock([]byte("server-secret"), func() time.Time { return day2 })
+
+	req := newRequest(t, "1.2.3.4:5555", "curl/8.0", "")
+
+	idBeforeMidnight := calcDay1.Calculate(req)
+
+	if !calcDay2.Matches(idBeforeMidnight, req) {
+		t.Errorf("expected Matches to still recognize a user ID minted just before UTC midnight, right after it rolled over")
+	}
+	if !calcDay2.Matches(calcDay2.Calculate(req), req) {
+		t.Errorf("expected Matches to recognize today's own user ID")
+	}
+
+	otherReq := newRequest(t, "9.9.9.9:1111", "curl/8.0", "")
+	if calcDay2.Matches(idBeforeMidnight, otherReq) {
+		t.Errorf("expected Matches to reject a user ID derived from a different caller")
+	}
+}
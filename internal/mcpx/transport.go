@@ -0,0 +1,220 @@
+// Package mcpx envolve o transporte MCP com reconexão automática, para que
+// uma queda do endpoint MCP - seja na conexão inicial, seja em produção -
+// não derrube o processo ou trave chamadas de ferramenta indefinidamente.
+package mcpx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultMaxConnRetries é o número de tentativas extras além da
+	// primeira conexão (MCP_MAX_CONN_RETRIES).
+	DefaultMaxConnRetries = 3
+	// DefaultBackoffMin é o atraso antes da primeira nova tentativa
+	// (MCP_BACKOFF_MIN).
+	DefaultBackoffMin = 500 * time.Millisecond
+	// DefaultBackoffMax é o teto do backoff exponencial (MCP_BACKOFF_MAX).
+	DefaultBackoffMax = 30 * time.Second
+)
+
+// Config controla as tentativas de conexão e o backoff entre elas.
+type Config struct {
+	MaxConnRetries int
+	BackoffMin     time.Duration
+	BackoffMax     time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxConnRetries <= 0 {
+		c.MaxConnRetries = DefaultMaxConnRetries
+	}
+	if c.BackoffMin <= 0 {
+		c.BackoffMin = DefaultBackoffMin
+	}
+	if c.BackoffMax <= 0 {
+		c.BackoffMax = DefaultBackoffMax
+	}
+	return c
+}
+
+// ResilientTransport implementa mcp.Transport sobre uma factory que produz
+// uma nova instância do transporte subjacente a cada tentativa de conexão -
+// o SDK do MCP exige uma instância nova por chamada a Client.Connect. A
+// conexão inicial usa backoff exponencial limitado (MaxConnRetries,
+// BackoffMin, BackoffMax); depois de conectado, falhas de leitura/escrita na
+// Connection ativa disparam uma reconexão transparente, sem derrubar a
+// ClientSession que a utiliza.
+type ResilientTransport struct {
+	newTransport func() mcp.Transport
+	cfg          Config
+
+	mu      sync.Mutex
+	ready   bool
+	lastErr error
+
+	reconnects int64
+}
+
+// New cria um ResilientTransport. newTransport deve retornar uma nova
+// instância do transporte subjacente (ex.: *mcp.StreamableClientTransport)
+// a cada chamada.
+func New(newTransport func() mcp.Transport, cfg Config) *ResilientTransport {
+	return &ResilientTransport{
+		newTransport: newTransport,
+		cfg:          cfg.withDefaults(),
+	}
+}
+
+// Connect estabelece a conexão inicial, tentando novamente com backoff
+// exponencial até MaxConnRetries vezes antes de desistir.
+func (t *ResilientTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.connectWithBackoff(ctx)
+	if err != nil {
+		t.setState(false, err)
+		return nil, err
+	}
+
+	t.setState(true, nil)
+	return &reconnectingConn{Connection: conn, rt: t}, nil
+}
+
+func (t *ResilientTransport) connectWithBackoff(ctx context.Context) (mcp.Connection, error) {
+	backoff := t.cfg.BackoffMin
+
+	var lastErr error
+	for attempt := 1; attempt <= t.cfg.MaxConnRetries+1; attempt++ {
+		conn, err := t.newTransport().Connect(ctx)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		log.Printf("mcpx: connect attempt %d/%d failed: %v", attempt, t.cfg.MaxConnRetries+1, err)
+
+		if attempt > t.cfg.MaxConnRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > t.cfg.BackoffMax {
+			backoff = t.cfg.BackoffMax
+		}
+	}
+
+	return nil, fmt.Errorf("mcpx: failed to connect after %d attempts: %w", t.cfg.MaxConnRetries+1, lastErr)
+}
+
+// reconnect is called by a reconnectingConn when it observes a transport
+// error, and counts towards the Reconnects metric.
+func (t *ResilientTransport) reconnect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.connectWithBackoff(ctx)
+	if err != nil {
+		t.setState(false, err)
+		return nil, err
+	}
+
+	atomic.AddInt64(&t.reconnects, 1)
+	t.setState(true, nil)
+	return conn, nil
+}
+
+func (t *ResilientTransport) setState(ready bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ready = ready
+	t.lastErr = err
+}
+
+// Ready reports whether the last (re)connect attempt succeeded.
+func (t *ResilientTransport) Ready() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.ready
+}
+
+// LastError returns the error from the last failed (re)connect attempt, if any.
+func (t *ResilientTransport) LastError() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastErr
+}
+
+// Reconnects reports how many times the transport has reconnected after the
+// initial connection - exposed as a Prometheus-style counter on /metrics.
+func (t *ResilientTransport) Reconnects() int64 {
+	return atomic.LoadInt64(&t.reconnects)
+}
+
+// reconnectingConn wraps a live mcp.Connection and transparently swaps in a
+// freshly reconnected one, through the owning ResilientTransport, whenever
+// Read or Write report an error.
+type reconnectingConn struct {
+	mcp.Connection
+	rt *ResilientTransport
+
+	mu sync.Mutex
+}
+
+func (c *reconnectingConn) current() mcp.Connection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Connection
+}
+
+func (c *reconnectingConn) swap(conn mcp.Connection) {
+	c.mu.Lock()
+	c.Connection = conn
+	c.mu.Unlock()
+}
+
+func (c *reconnectingConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.current().Read(ctx)
+	if err == nil || ctx.Err() != nil {
+		return msg, err
+	}
+
+	newConn, reconnErr := c.rt.reconnect(ctx)
+	if reconnErr != nil {
+		return nil, err
+	}
+	c.swap(newConn)
+
+	return newConn.Read(ctx)
+}
+
+func (c *reconnectingConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	err := c.current().Write(ctx, msg)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	newConn, reconnErr := c.rt.reconnect(ctx)
+	if reconnErr != nil {
+		return err
+	}
+	c.swap(newConn)
+
+	return newConn.Write(ctx, msg)
+}
+
+func (c *reconnectingConn) Close() error {
+	return c.current().Close()
+}
+
+func (c *reconnectingConn) SessionID() string {
+	return c.current().SessionID()
+}
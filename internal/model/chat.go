@@ -1,9 +1,14 @@
 package model
 
+import "google.golang.org/genai"
+
 // ChatRequest representa a requisição para o endpoint de chat
 type ChatRequest struct {
 	Message   string `json:"message"`
 	SessionID string `json:"session_id,omitempty"`
+	// Agent seleciona o agente registrado a usar (ver internal/registry).
+	// Quando vazio, cai para o agente padrão do servidor.
+	Agent string `json:"agent,omitempty"`
 }
 
 // ChatResponse representa a resposta do endpoint de chat
@@ -12,3 +17,18 @@ type ChatResponse struct {
 	SessionID string `json:"session_id"`
 	Error     string `json:"error,omitempty"`
 }
+
+// StreamEvent representa o payload JSON carregado por cada frame SSE
+// emitido por HandleChatStream. Apenas os campos relevantes para o tipo
+// de evento (ver `event:` do frame) são preenchidos.
+type StreamEvent struct {
+	SessionID        string                  `json:"session_id"`
+	Delta            string                  `json:"delta,omitempty"`
+	FunctionCall     *genai.FunctionCall     `json:"function_call,omitempty"`
+	FunctionResponse *genai.FunctionResponse `json:"function_response,omitempty"`
+	Error            string                  `json:"error,omitempty"`
+	// RedirectTo, presente só no evento "redirect", é o endereço do nó dono
+	// da sessão em modo cluster - o WebSocket não pode responder com um 307
+	// HTTP depois do upgrade, então o cliente deve reconectar lá.
+	RedirectTo string `json:"redirect_to,omitempty"`
+}
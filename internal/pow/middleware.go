@@ -0,0 +1,28 @@
+package pow
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Middleware enforces that requests carry a valid, unredeemed
+// proof-of-work solution in the X-Pow-Seed/X-Pow-Nonce headers before
+// reaching next. It can be mounted on any handler in internal/handler via
+// chi's r.With(...).
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seed := r.Header.Get("X-Pow-Seed")
+		nonce := r.Header.Get("X-Pow-Nonce")
+		if seed == "" || nonce == "" {
+			http.Error(w, "Proof of work required: missing X-Pow-Seed/X-Pow-Nonce headers", http.StatusPaymentRequired)
+			return
+		}
+
+		if err := m.Verify(seed, nonce); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid proof of work: %v", err), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,163 @@
+// Package pow implementa um gate de proof-of-work para proteger endpoints
+// públicos que disparam chamadas caras (LLM + MCP) contra abuso automatizado.
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidSeed indica que o seed não pôde ser decodificado ou sua
+	// assinatura HMAC não confere.
+	ErrInvalidSeed = errors.New("pow: invalid seed")
+	// ErrSeedExpired indica que o seed passou do seu prazo de validade.
+	ErrSeedExpired = errors.New("pow: seed expired")
+	// ErrSeedAlreadyUsed indica que o seed já foi redimido anteriormente.
+	ErrSeedAlreadyUsed = errors.New("pow: seed already used")
+	// ErrDifficultyNotMet indica que o nonce informado não satisfaz o
+	// número de bits zero exigido pelo desafio.
+	ErrDifficultyNotMet = errors.New("pow: difficulty not met")
+)
+
+// Challenge é a resposta de GET /api/pow/challenge.
+type Challenge struct {
+	Seed      string    `json:"seed"`
+	Target    int       `json:"target"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Manager emite e valida desafios de proof-of-work. O seed é assinado com
+// HMAC-SHA256 usando um segredo do servidor, então não precisa de
+// armazenamento persistente para ser verificado - apenas os seeds já
+// redimidos são guardados em memória, com TTL igual à validade do seed,
+// para impedir replay.
+type Manager struct {
+	secret         []byte
+	difficultyBits int
+	seedTTL        time.Duration
+
+	mu       sync.Mutex
+	redeemed map[string]time.Time // seed -> momento em que pode ser esquecido
+}
+
+// NewManager cria um Manager com o segredo, a dificuldade (em bits de zero
+// à esquerda) e o TTL de seed informados.
+func NewManager(secret []byte, difficultyBits int, seedTTL time.Duration) *Manager {
+	return &Manager{
+		secret:         secret,
+		difficultyBits: difficultyBits,
+		seedTTL:        seedTTL,
+		redeemed:       make(map[string]time.Time),
+	}
+}
+
+// NewChallenge gera um novo desafio assinado.
+func (m *Manager) NewChallenge() (Challenge, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return Challenge{}, fmt.Errorf("pow: failed to generate random seed: %w", err)
+	}
+	random := hex.EncodeToString(randomBytes)
+	issuedAt := time.Now()
+	seed := fmt.Sprintf("%s.%d.%s", random, issuedAt.Unix(), m.sign(random, issuedAt.Unix()))
+
+	return Challenge{
+		Seed:      seed,
+		Target:    m.difficultyBits,
+		ExpiresAt: issuedAt.Add(m.seedTTL),
+	}, nil
+}
+
+// Verify confere a assinatura e a validade do seed, garante que ele ainda
+// não foi redimido, e checa que sha256(seed||nonce) tem pelo menos
+// difficultyBits de zero à esquerda.
+func (m *Manager) Verify(seed, nonce string) error {
+	random, issuedAt, sig, err := splitSeed(seed)
+	if err != nil {
+		return ErrInvalidSeed
+	}
+
+	if !hmac.Equal([]byte(m.sign(random, issuedAt)), []byte(sig)) {
+		return ErrInvalidSeed
+	}
+
+	issuedTime := time.Unix(issuedAt, 0)
+	if time.Since(issuedTime) > m.seedTTL {
+		return ErrSeedExpired
+	}
+
+	hash := sha256.Sum256([]byte(seed + nonce))
+	if !hasLeadingZeroBits(hash[:], m.difficultyBits) {
+		return ErrDifficultyNotMet
+	}
+
+	if err := m.redeem(seed, issuedTime.Add(m.seedTTL)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// redeem marca o seed como usado, rejeitando reuso, e aproveita a chamada
+// para varrer entradas já expiradas do mapa.
+func (m *Manager) redeem(seed string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if exp, ok := m.redeemed[seed]; ok && now.Before(exp) {
+		return ErrSeedAlreadyUsed
+	}
+
+	for s, exp := range m.redeemed {
+		if now.After(exp) {
+			delete(m.redeemed, s)
+		}
+	}
+
+	m.redeemed[seed] = expiresAt
+	return nil
+}
+
+func (m *Manager) sign(random string, issuedAt int64) string {
+	mac := hmac.New(sha256.New, m.secret)
+	fmt.Fprintf(mac, "%s:%d", random, issuedAt)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func splitSeed(seed string) (random string, issuedAt int64, sig string, err error) {
+	parts := strings.SplitN(seed, ".", 3)
+	if len(parts) != 3 {
+		return "", 0, "", ErrInvalidSeed
+	}
+	issuedAt, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, "", ErrInvalidSeed
+	}
+	return parts[0], issuedAt, parts[2], nil
+}
+
+// hasLeadingZeroBits reports whether b has at least `bits` leading zero
+// bits when read most-significant-bit first.
+func hasLeadingZeroBits(b []byte, bits int) bool {
+	for i := 0; i < bits; i++ {
+		byteIdx := i / 8
+		bitIdx := 7 - (i % 8)
+		if byteIdx >= len(b) {
+			return false
+		}
+		if b[byteIdx]&(1<<uint(bitIdx)) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MCPEndpointConfig descreve um servidor MCP que um agente pode usar,
+// incluindo suas próprias credenciais - agentes diferentes podem falar com
+// servidores (ou credenciais) diferentes.
+type MCPEndpointConfig struct {
+	URL      string `yaml:"url" json:"url"`
+	Token    string `yaml:"token" json:"token"`
+	TokenEnv string `yaml:"token_env" json:"token_env"`
+}
+
+// ResolveToken retorna o token a usar para este endpoint: o literal Token,
+// se presente, senão o valor da variável de ambiente TokenEnv.
+func (c MCPEndpointConfig) ResolveToken() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	if c.TokenEnv != "" {
+		return os.Getenv(c.TokenEnv)
+	}
+	return ""
+}
+
+// AgentConfig descreve um agente declarado em AGENTS_CONFIG.
+type AgentConfig struct {
+	Name        string              `yaml:"name" json:"name"`
+	Description string              `yaml:"description" json:"description"`
+	Instruction string              `yaml:"instruction" json:"instruction"`
+	Model       string              `yaml:"model" json:"model"`
+	Default     bool                `yaml:"default" json:"default"`
+	MCP         []MCPEndpointConfig `yaml:"mcp" json:"mcp"`
+}
+
+// FileConfig é a raiz do arquivo apontado por AGENTS_CONFIG.
+type FileConfig struct {
+	Agents []AgentConfig `yaml:"agents" json:"agents"`
+}
+
+// LoadConfig lê e decodifica path como YAML ou JSON, conforme a extensão.
+func LoadConfig(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agents config %s: %w", path, err)
+	}
+
+	var cfg FileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse agents config %s as yaml: %w", path, err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse agents config %s as json: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported agents config extension %q (want .yaml, .yml or .json)", ext)
+	}
+
+	if len(cfg.Agents) == 0 {
+		return nil, fmt.Errorf("agents config %s declares no agents", path)
+	}
+
+	return &cfg, nil
+}
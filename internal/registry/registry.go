@@ -0,0 +1,84 @@
+// Package registry mantém os agentes disponíveis no servidor pelo nome,
+// permitindo que cada requisição de chat seja roteada para o agente pedido
+// em vez de um único agente fixo.
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"google.golang.org/adk/agent"
+)
+
+// AgentInfo resume um agente registrado, para exposição em GET /api/agents.
+type AgentInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ToolCount   int    `json:"tool_count"`
+}
+
+// Registry mantém os agentes registrados pelo nome.
+type Registry struct {
+	mu          sync.RWMutex
+	agents      map[string]agent.Agent
+	infos       map[string]AgentInfo
+	defaultName string
+}
+
+// New cria um Registry vazio. defaultName é o agente usado quando uma
+// requisição não especifica um.
+func New(defaultName string) *Registry {
+	return &Registry{
+		agents:      make(map[string]agent.Agent),
+		infos:       make(map[string]AgentInfo),
+		defaultName: defaultName,
+	}
+}
+
+// Register adiciona (ou substitui) um agente pelo nome. toolCount é o
+// número de toolsets MCP configurados para o agente - uma contagem exata de
+// ferramentas exigiria um agent.ReadonlyContext vivo, que só existe durante
+// uma execução, então usamos a contagem estática vinda da configuração.
+func (r *Registry) Register(name string, a agent.Agent, description string, toolCount int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.agents[name] = a
+	r.infos[name] = AgentInfo{Name: name, Description: description, ToolCount: toolCount}
+}
+
+// Get retorna o agente pelo nome, caindo para o agente padrão quando name
+// está vazio.
+func (r *Registry) Get(name string) (agent.Agent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if name == "" {
+		name = r.defaultName
+	}
+
+	a, ok := r.agents[name]
+	if !ok {
+		return nil, fmt.Errorf("registry: unknown agent %q", name)
+	}
+	return a, nil
+}
+
+// DefaultName retorna o nome do agente padrão.
+func (r *Registry) DefaultName() string {
+	return r.defaultName
+}
+
+// List retorna os agentes registrados, ordenados por nome.
+func (r *Registry) List() []AgentInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]AgentInfo, 0, len(r.infos))
+	for _, info := range r.infos {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
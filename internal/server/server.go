@@ -2,10 +2,13 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
-	"os"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -20,7 +23,24 @@ import (
 	"google.golang.org/adk/tool/mcptoolset"
 	"google.golang.org/genai"
 
+	"github.com/vitormoschetta/go-adk/internal/cluster"
+	"github.com/vitormoschetta/go-adk/internal/config"
+	"github.com/vitormoschetta/go-adk/internal/identity"
+	"github.com/vitormoschetta/go-adk/internal/mcpx"
+	"github.com/vitormoschetta/go-adk/internal/pow"
+	"github.com/vitormoschetta/go-adk/internal/registry"
 	"github.com/vitormoschetta/go-adk/internal/service"
+	sessionstore "github.com/vitormoschetta/go-adk/internal/session"
+)
+
+const (
+	defaultPowDifficultyBits = 20
+	defaultPowSeedTTL        = 5 * time.Minute
+
+	mcpImplementationName = "go-adk-http-server"
+
+	defaultAgentName  = "helper_agent"
+	defaultAgentModel = "gemini-2.5-flash"
 )
 
 // AuthenticatedTransport adiciona headers de autenticação às requisições HTTP
@@ -51,97 +71,374 @@ func (t *AuthenticatedTransport) RoundTrip(req *http.Request) (*http.Response, e
 
 // Server representa o servidor HTTP com todas as dependências
 type Server struct {
-	Agent          agent.Agent
-	AgentRunner    *runner.Runner
-	SessionManager *service.SessionManager
-	SessionService session.Service
-	McpEndpoint    string
-	Router         chi.Router
+	Cfg                *config.Config
+	Registry           *registry.Registry
+	Runners            map[string]*runner.Runner
+	SessionManager     *service.SessionManager
+	SessionService     session.Service
+	McpTransports      map[string]*mcpx.ResilientTransport
+	McpSessions        map[string]*mcp.ClientSession
+	McpEndpoints       map[string]string
+	PowManager         *pow.Manager
+	IdentityCalculator *identity.UserIDCalculator
+	Router             chi.Router
+
+	// Cluster é nil quando o servidor roda como instância única (NODE_ID não
+	// definido - ver internal/cluster.Config.Enabled).
+	Cluster *cluster.Cluster
+
+	httpServer      *http.Server
+	clusterListener net.Listener
 }
 
-// NewServer cria uma nova instância do servidor
-func NewServer(ctx context.Context) (*Server, error) {
-	// Criar o modelo LLM
-	llmModel, err := gemini.NewModel(ctx, "gemini-2.5-flash", &genai.ClientConfig{
-		APIKey: os.Getenv("GOOGLE_API_KEY"),
-	})
+// NewServer cria uma nova instância do servidor a partir da Config já
+// carregada (ver internal/config.Load).
+func NewServer(ctx context.Context, cfg *config.Config) (*Server, error) {
+	agentConfigs, err := loadAgentConfigs(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create model: %w", err)
+		return nil, err
+	}
+
+	// Criar session service (compartilhado: o agrupamento por AppName já é
+	// suficiente, o isolamento por usuário/sessão é feito pelo SessionManager)
+	sessionService := session.InMemoryService()
+
+	reg := registry.New(pickDefaultAgentName(agentConfigs))
+	runners := make(map[string]*runner.Runner, len(agentConfigs))
+	mcpTransports := make(map[string]*mcpx.ResilientTransport, len(agentConfigs))
+	mcpSessions := make(map[string]*mcp.ClientSession, len(agentConfigs))
+	mcpEndpoints := make(map[string]string, len(agentConfigs))
+
+	for _, ac := range agentConfigs {
+		a, mcpTransport, mcpSession, mcpEndpoint, err := buildAgent(ctx, cfg, ac)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build agent %q: %w", ac.Name, err)
+		}
+
+		agentRunner, err := runner.New(runner.Config{
+			AppName:        mcpImplementationName,
+			Agent:          a,
+			SessionService: sessionService,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create runner for agent %q: %w", ac.Name, err)
+		}
+
+		reg.Register(ac.Name, a, ac.Description, len(ac.MCP))
+		runners[ac.Name] = agentRunner
+		if mcpTransport != nil {
+			mcpTransports[ac.Name] = mcpTransport
+			mcpSessions[ac.Name] = mcpSession
+			mcpEndpoints[ac.Name] = mcpEndpoint
+		}
+	}
+
+	// Lido cedo porque o modo cluster muda como o segredo HMAC de
+	// newPowManager/newIdentityCalculator deve ser obtido (ver seus comentários).
+	clusterCfg, err := cluster.FromEnv(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster config: %w", err)
+	}
+
+	powManager, err := newPowManager(cfg, clusterCfg.Enabled())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pow manager: %w", err)
+	}
+
+	identityCalculator, err := newIdentityCalculator(cfg, clusterCfg.Enabled())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity calculator: %w", err)
+	}
+
+	// Store de persistência das ChatSession (SESSION_BACKEND=memory|redis|sqlite|boltdb)
+	store, sessionTTL, err := sessionstore.NewStoreFromEnv(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session store: %w", err)
+	}
+
+	// Modo cluster opcional (NODE_ID definido): descoberta via Serf e
+	// replicação do Store via Raft, para rodar várias instâncias em HA.
+	var clusterNode *cluster.Cluster
+	var clusterListener net.Listener
+	if clusterCfg.Enabled() {
+		clusterNode, clusterListener, err = cluster.New(clusterCfg, store)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start cluster: %w", err)
+		}
+	}
+
+	s := &Server{
+		Cfg:                cfg,
+		Registry:           reg,
+		Runners:            runners,
+		SessionManager:     service.NewSessionManager(store, sessionTTL),
+		SessionService:     sessionService,
+		McpTransports:      mcpTransports,
+		McpSessions:        mcpSessions,
+		McpEndpoints:       mcpEndpoints,
+		PowManager:         powManager,
+		IdentityCalculator: identityCalculator,
+		Cluster:            clusterNode,
+		clusterListener:    clusterListener,
+	}
+
+	return s, nil
+}
+
+// loadAgentConfigs retorna os agentes a registrar: o arquivo apontado por
+// AGENTS_CONFIG (YAML ou JSON) quando definido, ou - para compatibilidade
+// com implantações de agente único - um único "helper_agent" montado a
+// partir de MCP_ENDPOINT/X_TIGER_TOKEN.
+func loadAgentConfigs(cfg *config.Config) ([]registry.AgentConfig, error) {
+	if cfgPath := cfg.Get("AGENTS_CONFIG"); cfgPath != "" {
+		fileCfg, err := registry.LoadConfig(cfgPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load agents config: %w", err)
+		}
+
+		for _, ac := range fileCfg.Agents {
+			if ac.Name == "" {
+				return nil, fmt.Errorf("agents config %s has an entry with no name", cfgPath)
+			}
+		}
+
+		return fileCfg.Agents, nil
 	}
 
-	mcpEndpoint := os.Getenv("MCP_ENDPOINT")
+	mcpEndpoint := cfg.Get("MCP_ENDPOINT")
 	if mcpEndpoint == "" {
 		return nil, fmt.Errorf("MCP_ENDPOINT is not set")
 	}
 
-	// Obter o token de autenticação
-	tigerToken := os.Getenv("X_TIGER_TOKEN")
+	tigerToken := cfg.Get("X_TIGER_TOKEN")
 	if tigerToken == "" {
 		log.Println("Warning: X_TIGER_TOKEN is not set - MCP requests may fail with 403")
 	}
 
-	// Criar HTTP client com autenticação customizada
+	return []registry.AgentConfig{{
+		Name:        defaultAgentName,
+		Description: "Helper agent with MCP tools.",
+		Instruction: "You are a helpful assistant that helps users with various tasks using MCP tools.",
+		Model:       defaultAgentModel,
+		Default:     true,
+		MCP: []registry.MCPEndpointConfig{{
+			URL:   mcpEndpoint,
+			Token: tigerToken,
+		}},
+	}}, nil
+}
+
+// pickDefaultAgentName retorna o nome marcado como default na configuração,
+// ou o primeiro agente declarado quando nenhum está marcado.
+func pickDefaultAgentName(agentConfigs []registry.AgentConfig) string {
+	for _, ac := range agentConfigs {
+		if ac.Default {
+			return ac.Name
+		}
+	}
+	return agentConfigs[0].Name
+}
+
+// buildAgent monta o agent.Agent de uma entrada de configuração: seu
+// próprio modelo e, para cada endpoint MCP declarado, seu próprio toolset
+// com credenciais independentes - permitindo que agentes diferentes falem
+// com servidores MCP (ou credenciais) diferentes. Retorna também o
+// ResilientTransport/ClientSession/endpoint do primeiro toolset, usados por
+// /health e /api/tools.
+func buildAgent(ctx context.Context, cfg *config.Config, ac registry.AgentConfig) (agent.Agent, *mcpx.ResilientTransport, *mcp.ClientSession, string, error) {
+	modelName := ac.Model
+	if modelName == "" {
+		modelName = defaultAgentModel
+	}
+
+	llmModel, err := gemini.NewModel(ctx, modelName, &genai.ClientConfig{
+		APIKey: cfg.Get("GOOGLE_API_KEY"),
+	})
+	if err != nil {
+		return nil, nil, nil, "", fmt.Errorf("failed to create model: %w", err)
+	}
+
+	var toolsets []tool.Toolset
+	var primaryTransport *mcpx.ResilientTransport
+	var primarySession *mcp.ClientSession
+	var primaryEndpoint string
+
+	for i, mcpCfg := range ac.MCP {
+		toolset, mcpTransport, mcpSession, err := newMcpToolset(ctx, cfg, ac.Name, mcpCfg)
+		if err != nil {
+			return nil, nil, nil, "", err
+		}
+
+		toolsets = append(toolsets, toolset)
+		if i == 0 {
+			primaryTransport = mcpTransport
+			primarySession = mcpSession
+			primaryEndpoint = mcpCfg.URL
+		}
+	}
+
+	a, err := llmagent.New(llmagent.Config{
+		Name:        ac.Name,
+		Model:       llmModel,
+		Description: ac.Description,
+		Instruction: ac.Instruction,
+		Toolsets:    toolsets,
+	})
+	if err != nil {
+		return nil, nil, nil, "", fmt.Errorf("failed to create agent: %w", err)
+	}
+
+	return a, primaryTransport, primarySession, primaryEndpoint, nil
+}
+
+// newMcpToolset conecta a um servidor MCP através de um transporte
+// resiliente (reconexão automática, backoff exponencial) e monta o toolset
+// correspondente.
+func newMcpToolset(ctx context.Context, cfg *config.Config, agentName string, mcpCfg registry.MCPEndpointConfig) (tool.Toolset, *mcpx.ResilientTransport, *mcp.ClientSession, error) {
 	httpClient := &http.Client{
 		Transport: &AuthenticatedTransport{
 			Base:  http.DefaultTransport,
-			Token: tigerToken,
+			Token: mcpCfg.ResolveToken(),
 		},
 		Timeout: 30 * time.Second,
 	}
 
-	// Criar MCP transport com o HTTP client autenticado
-	transport := &mcp.StreamableClientTransport{
-		Endpoint:   mcpEndpoint,
-		HTTPClient: httpClient,
-	}
+	mcpTransport := mcpx.New(func() mcp.Transport {
+		return &mcp.StreamableClientTransport{
+			Endpoint:   mcpCfg.URL,
+			HTTPClient: httpClient,
+		}
+	}, mcpConfigFromEnv(cfg))
 
-	log.Printf("🔌 Connecting to MCP endpoint: %s", mcpEndpoint)
+	log.Printf("🔌 Connecting agent %q to MCP endpoint: %s", agentName, mcpCfg.URL)
+
+	mcpSession, err := mcp.NewClient(&mcp.Implementation{
+		Name:    mcpImplementationName,
+		Version: "1.0.0",
+	}, nil).Connect(ctx, mcpTransport, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to MCP endpoint %s: %w", mcpCfg.URL, err)
+	}
 
 	mcpToolSet, err := mcptoolset.New(mcptoolset.Config{
-		Transport: transport,
+		Transport: mcpTransport,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create MCP tool set: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to create MCP tool set for %s: %w", mcpCfg.URL, err)
 	}
 
-	log.Printf("✅ MCP toolset initialized successfully")
+	log.Printf("✅ MCP toolset initialized for agent %q", agentName)
 
-	// Criar LLMAgent com MCP tool set
-	a, err := llmagent.New(llmagent.Config{
-		Name:        "helper_agent",
-		Model:       llmModel,
-		Description: "Helper agent with MCP tools.",
-		Instruction: "You are a helpful assistant that helps users with various tasks using MCP tools.",
-		Toolsets: []tool.Toolset{
-			mcpToolSet,
-		},
-	})
+	return mcpToolSet, mcpTransport, mcpSession, nil
+}
+
+// mcpConfigFromEnv lê MCP_MAX_CONN_RETRIES, MCP_BACKOFF_MIN e MCP_BACKOFF_MAX,
+// caindo para os padrões do pacote mcpx quando ausentes ou inválidos.
+func mcpConfigFromEnv(cfg *config.Config) mcpx.Config {
+	mcpCfg := mcpx.Config{}
+
+	if v := cfg.Get("MCP_MAX_CONN_RETRIES"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			mcpCfg.MaxConnRetries = parsed
+		} else {
+			log.Printf("Warning: invalid MCP_MAX_CONN_RETRIES %q, using default", v)
+		}
+	}
+
+	if v := cfg.Get("MCP_BACKOFF_MIN"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil {
+			mcpCfg.BackoffMin = parsed
+		} else {
+			log.Printf("Warning: invalid MCP_BACKOFF_MIN %q, using default", v)
+		}
+	}
+
+	if v := cfg.Get("MCP_BACKOFF_MAX"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil {
+			mcpCfg.BackoffMax = parsed
+		} else {
+			log.Printf("Warning: invalid MCP_BACKOFF_MAX %q, using default", v)
+		}
+	}
+
+	return mcpCfg
+}
+
+// newPowManager monta o gate de proof-of-work com a dificuldade e o TTL de
+// seed configurados via env (POW_DIFFICULTY_BITS, POW_SEED_TTL), caindo
+// para os padrões quando ausentes; um valor inválido é retornado como erro.
+// O segredo HMAC vem de
+// POW_SECRET quando definido (obrigatório em modo cluster - ver
+// secretFromConfig) ou é gerado por processo caso contrário.
+func newPowManager(cfg *config.Config, clustered bool) (*pow.Manager, error) {
+	difficultyBits := defaultPowDifficultyBits
+	if v := cfg.Get("POW_DIFFICULTY_BITS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid POW_DIFFICULTY_BITS %q: %w", v, err)
+		}
+		difficultyBits = parsed
+	}
+
+	seedTTL := defaultPowSeedTTL
+	if v := cfg.Get("POW_SEED_TTL"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid POW_SEED_TTL %q: %w", v, err)
+		}
+		seedTTL = parsed
+	}
+
+	secret, err := secretFromConfig(cfg, "POW_SECRET", clustered)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create agent: %w", err)
+		return nil, err
 	}
 
-	// Criar session service (in-memory for HTTP server)
-	sessionService := session.InMemoryService()
+	return pow.NewManager(secret, difficultyBits, seedTTL), nil
+}
 
-	// Criar runner para executar o agente
-	agentRunner, err := runner.New(runner.Config{
-		AppName:        "go-adk-http-server",
-		Agent:          a,
-		SessionService: sessionService,
-	})
+// newIdentityCalculator monta o UserIDCalculator usado para derivar o ID de
+// usuário de cada requisição. O segredo HMAC vem de IDENTITY_SECRET quando
+// definido (obrigatório em modo cluster) ou é gerado por processo caso
+// contrário.
+func newIdentityCalculator(cfg *config.Config, clustered bool) (*identity.UserIDCalculator, error) {
+	secret, err := secretFromConfig(cfg, "IDENTITY_SECRET", clustered)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create runner: %w", err)
+		return nil, err
 	}
 
-	s := &Server{
-		Agent:          a,
-		AgentRunner:    agentRunner,
-		SessionManager: service.NewSessionManager(),
-		SessionService: sessionService,
-		McpEndpoint:    mcpEndpoint,
+	return identity.NewUserIDCalculator(secret), nil
+}
+
+// secretFromConfig lê um segredo HMAC de pelo menos 32 bytes (64 ou mais
+// caracteres hex) da
+// variável envKey, ou gera um aleatório por processo quando ela não está
+// definida. Um segredo gerado por processo torna PoW challenges e userIDs
+// específicos deste nó - inválidos em qualquer outro - então, em modo
+// cluster, exige que envKey esteja definido (o mesmo valor em todos os nós)
+// em vez de silenciosamente quebrar challenges/sessões entre nós atrás de
+// um load balancer.
+func secretFromConfig(cfg *config.Config, envKey string, clustered bool) ([]byte, error) {
+	if v := cfg.Get(envKey); v != "" {
+		secret, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: must be hex-encoded: %w", envKey, err)
+		}
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("invalid %s: must be at least 32 bytes (64 hex chars)", envKey)
+		}
+		return secret, nil
 	}
 
-	return s, nil
+	if clustered {
+		return nil, fmt.Errorf("%s must be set (and identical across nodes) in cluster mode", envKey)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate %s: %w", envKey, err)
+	}
+	return secret, nil
 }
 
 // SetupRouter configura as rotas e middlewares do Chi
@@ -149,7 +446,16 @@ func (s *Server) SetupRouter(
 	handleRoot func(http.ResponseWriter, *http.Request),
 	handleHealth func(http.ResponseWriter, *http.Request),
 	handleChat func(http.ResponseWriter, *http.Request),
+	handleChatStream func(http.ResponseWriter, *http.Request),
 	handleTools func(http.ResponseWriter, *http.Request),
+	handlePowChallenge func(http.ResponseWriter, *http.Request),
+	handleDeleteSession func(http.ResponseWriter, *http.Request),
+	handleMetrics func(http.ResponseWriter, *http.Request),
+	handleAgents func(http.ResponseWriter, *http.Request),
+	handleChatStreamSSE func(http.ResponseWriter, *http.Request),
+	handleChatStreamWS func(http.ResponseWriter, *http.Request),
+	handleClusterStatus func(http.ResponseWriter, *http.Request),
+	handleFeatures func(http.ResponseWriter, *http.Request),
 ) {
 	r := chi.NewRouter()
 
@@ -163,20 +469,44 @@ func (s *Server) SetupRouter(
 	// Rotas
 	r.Get("/", handleRoot)
 	r.Get("/health", handleHealth)
+	r.Get("/metrics", handleMetrics)
 
 	// API Routes
 	r.Route("/api", func(r chi.Router) {
-		r.Post("/chat", handleChat)
+		r.Get("/pow/challenge", handlePowChallenge)
 		r.Get("/tools", handleTools)
+		r.Get("/agents", handleAgents)
+		r.Delete("/sessions/{id}", handleDeleteSession)
+
+		// Chat routes trigger paid LLM + MCP calls, so they sit behind the
+		// proof-of-work gate.
+		r.With(s.PowManager.Middleware).Post("/chat", handleChat)
+		r.With(s.PowManager.Middleware).Post("/chat/stream", handleChatStream)
 	})
 
+	// Streaming chat endpoints at the top level (distinct from /api/chat/stream):
+	// /chat/stream mirrors it over SSE with heartbeats, /chat/ws adds
+	// bidirectional WebSocket streaming so a client can cancel a turn mid-
+	// generation.
+	r.With(s.PowManager.Middleware).Post("/chat/stream", handleChatStreamSSE)
+	r.With(s.PowManager.Middleware).Get("/chat/ws", handleChatStreamWS)
+
+	r.Get("/cluster/status", handleClusterStatus)
+	r.Get("/config/features", handleFeatures)
+
+	// Rota interna usada por Cluster.forwardPut para encaminhar escritas de um
+	// seguidor ao líder Raft - só existe em modo cluster.
+	if s.Cluster != nil {
+		r.Post("/internal/cluster/apply", s.Cluster.HandleApply)
+	}
+
 	s.Router = r
 }
 
-// Start inicia o servidor HTTP com graceful shutdown
+// Start inicia o servidor HTTP em segundo plano e retorna imediatamente; o
+// chamador decide quando parar e chama Shutdown.
 func (s *Server) Start(ctx context.Context) {
-	// Configurar servidor HTTP com graceful shutdown
-	httpServer := &http.Server{
+	s.httpServer = &http.Server{
 		Addr:         ":8080",
 		Handler:      s.Router,
 		ReadTimeout:  15 * time.Second,
@@ -184,7 +514,6 @@ func (s *Server) Start(ctx context.Context) {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	// Goroutine para iniciar o servidor
 	go func() {
 		log.Println("╔════════════════════════════════════════════════════╗")
 		log.Println("║   ADK Agent HTTP Server com MCP Tools             ║")
@@ -197,6 +526,7 @@ func (s *Server) Start(ctx context.Context) {
 		log.Println("   • Info:      http://localhost:8080/ (GET)")
 		log.Println("   • Health:    http://localhost:8080/health (GET)")
 		log.Println("   • Chat API:  http://localhost:8080/api/chat (POST)")
+		log.Println("   • Agents:    http://localhost:8080/api/agents (GET)")
 		log.Println("   • Tools:     http://localhost:8080/api/tools (GET)")
 		log.Println("")
 		log.Println("💡 Exemplo de uso com curl:")
@@ -207,21 +537,35 @@ func (s *Server) Start(ctx context.Context) {
 		log.Println("⚠️  Pressione Ctrl+C para parar o servidor")
 		log.Println("")
 
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		// Em modo cluster, a porta já está ligada (e multiplexada com o Raft
+		// via cmux) em s.clusterListener; fora dele, o http.Server liga a
+		// sua própria porta normalmente.
+		var err error
+		if s.clusterListener != nil {
+			err = s.httpServer.Serve(s.clusterListener)
+		} else {
+			err = s.httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server failed: %v", err)
 		}
 	}()
+}
 
-	// Aguardar sinal de interrupção
-	<-ctx.Done()
-	log.Println("\n🛑 Shutting down server...")
+// Shutdown para de aceitar novas conexões e aguarda as requisições em
+// andamento terminarem, respeitando o prazo de ctx - mesma semântica de
+// http.Server.Shutdown. Em modo cluster, também sai do gossip do Serf e
+// desliga o nó Raft.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
 
-	// Graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	err := s.httpServer.Shutdown(ctx)
 
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		log.Printf("❌ Server shutdown error: %v", err)
+	if s.Cluster != nil {
+		s.Cluster.Shutdown()
 	}
-	log.Println("✅ Server stopped gracefully")
+
+	return err
 }
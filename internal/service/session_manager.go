@@ -1,35 +1,75 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
 	"sync"
 	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/genai"
+
+	sessionstore "github.com/vitormoschetta/go-adk/internal/session"
 )
 
+// ErrSessionUserMismatch é retornado por GetOrCreate quando o sessionID
+// informado já pertence a outro usuário, para que o handler rejeite a
+// requisição em vez de vazar o histórico de um usuário para outro.
+var ErrSessionUserMismatch = errors.New("session: session id belongs to a different user")
+
 // ChatSession representa uma sessão de conversação HTTP
 type ChatSession struct {
 	ID      string
+	UserID  string
 	Agent   agent.Agent
 	History []*genai.Content
 	Mu      sync.Mutex
 }
 
-// SessionManager gerencia sessões de conversação HTTP
+// SessionManager gerencia sessões de conversação HTTP. Mantém um cache em
+// memória por instância (o Mu de cada ChatSession só faz sentido localmente)
+// e persiste o histórico no Store configurado via SESSION_BACKEND, para que
+// o servidor escale horizontalmente e sobreviva a restarts.
 type SessionManager struct {
 	sessions map[string]*ChatSession
 	mu       sync.RWMutex
+
+	store  sessionstore.Store
+	ttl    time.Duration
+	stopCh chan struct{}
 }
 
-func NewSessionManager() *SessionManager {
-	return &SessionManager{
+// NewSessionManager cria um SessionManager apoiado no Store informado e
+// inicia o janitor que descarta do cache local sessões cujo registro já
+// expirou no Store.
+func NewSessionManager(store sessionstore.Store, ttl time.Duration) *SessionManager {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+
+	sm := &SessionManager{
 		sessions: make(map[string]*ChatSession),
+		store:    store,
+		ttl:      ttl,
+		stopCh:   make(chan struct{}),
 	}
+	go sm.runJanitor()
+	return sm
 }
 
-// GetOrCreate obtém uma sessão existente ou cria uma nova
-func (sm *SessionManager) GetOrCreate(sessionID string, a agent.Agent) *ChatSession {
+// GetOrCreate obtém uma sessão existente - primeiro no cache local, depois
+// reidratando do Store - ou cria uma nova, escopada ao (userID, sessionID)
+// informado. Se o sessionID já existir e sameUser rejeitar o UserID
+// guardado, retorna ErrSessionUserMismatch em vez de entregar o histórico de
+// um usuário a outro. A checagem de posse passa por sameUser em vez de uma
+// simples igualdade de string porque userID pode rotacionar com o tempo (ver
+// identity.UserIDCalculator.Matches) - comparar userID diretamente rejeitaria
+// o dono legítimo da sessão assim que o ID dele rotacionasse.
+func (sm *SessionManager) GetOrCreate(ctx context.Context, sessionID, userID string, sameUser func(storedUserID string) bool, a agent.Agent) (*ChatSession, error) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -38,18 +78,176 @@ func (sm *SessionManager) GetOrCreate(sessionID string, a agent.Agent) *ChatSess
 	}
 
 	if chatSession, exists := sm.sessions[sessionID]; exists {
-		return chatSession
+		if !sameUser(chatSession.UserID) {
+			return nil, ErrSessionUserMismatch
+		}
+		return chatSession, nil
 	}
 
 	chatSession := &ChatSession{
 		ID:      sessionID,
+		UserID:  userID,
 		Agent:   a,
 		History: []*genai.Content{},
 	}
+
+	if rec, err := sm.store.Get(ctx, sessionID); err == nil {
+		if !sameUser(rec.UserID) {
+			return nil, ErrSessionUserMismatch
+		}
+		if history, decodeErr := decodeHistory(rec.History); decodeErr == nil {
+			chatSession.History = history
+		} else {
+			log.Printf("Failed to decode persisted history for session %s: %v", sessionID, decodeErr)
+		}
+	}
+
 	sm.sessions[sessionID] = chatSession
-	return chatSession
+	return chatSession, nil
+}
+
+// Persist grava o estado atual da sessão no Store. Deve ser chamada pelo
+// handler ao final de cada turno, com cs.Mu já travado pelo chamador.
+func (sm *SessionManager) Persist(ctx context.Context, cs *ChatSession) error {
+	data, err := encodeHistory(cs.History)
+	if err != nil {
+		return err
+	}
+
+	return sm.store.Put(ctx, cs.ID, &sessionstore.Record{
+		SessionID: cs.ID,
+		UserID:    cs.UserID,
+		History:   data,
+		UpdatedAt: time.Now(),
+	}, sm.ttl)
+}
+
+// TTL retorna o tempo de vida configurado para os registros de sessão no
+// Store, usado por quem grava sessões fora do Persist (ex: o modo cluster,
+// que replica via Raft em vez de escrever direto no Store local).
+func (sm *SessionManager) TTL() time.Duration {
+	return sm.ttl
+}
+
+// Delete remove a sessão do cache local e do Store.
+func (sm *SessionManager) Delete(ctx context.Context, sessionID string) error {
+	sm.mu.Lock()
+	delete(sm.sessions, sessionID)
+	sm.mu.Unlock()
+
+	return sm.store.Delete(ctx, sessionID)
+}
+
+// runJanitor varre periodicamente o cache local e descarta as sessões cujo
+// registro já expirou no Store, liberando memória de instâncias que ficam
+// no ar por muito tempo.
+func (sm *SessionManager) runJanitor() {
+	ticker := time.NewTicker(sm.ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sm.evictExpired()
+		case <-sm.stopCh:
+			return
+		}
+	}
 }
 
+func (sm *SessionManager) evictExpired() {
+	ctx := context.Background()
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for id := range sm.sessions {
+		if _, err := sm.store.Get(ctx, id); err == sessionstore.ErrNotFound {
+			delete(sm.sessions, id)
+		}
+	}
+}
+
+// Close para o janitor e aguarda que as sessões atualmente em uso (cujo Mu
+// está travado por uma requisição em andamento) liberem seu lock, até que
+// ctx expire. Sessões ainda travadas quando o prazo expira são logadas para
+// que o operador saiba quais turnos de agente estavam em andamento no
+// shutdown.
+func (sm *SessionManager) Close(ctx context.Context) error {
+	close(sm.stopCh)
+
+	sm.mu.RLock()
+	sessions := make([]*ChatSession, 0, len(sm.sessions))
+	for _, cs := range sm.sessions {
+		sessions = append(sessions, cs)
+	}
+	sm.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	done := make(chan string, len(sessions))
+	for _, cs := range sessions {
+		wg.Add(1)
+		go func(cs *ChatSession) {
+			defer wg.Done()
+			cs.Mu.Lock()
+			cs.Mu.Unlock()
+			done <- cs.ID
+		}(cs)
+	}
+
+	allDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allDone)
+	}()
+
+	finished := make(map[string]bool, len(sessions))
+	for {
+		select {
+		case id := <-done:
+			finished[id] = true
+		case <-allDone:
+			return nil
+		case <-ctx.Done():
+			var stillActive []string
+			for _, cs := range sessions {
+				if !finished[cs.ID] {
+					stillActive = append(stillActive, cs.ID)
+				}
+			}
+			if len(stillActive) > 0 {
+				log.Printf("Session drain deadline hit with %d session(s) still active: %v", len(stillActive), stillActive)
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// generateSessionID gera um ID aleatório de 16 bytes em hexadecimal. Usar
+// apenas o timestamp (como antes) colidia entre requisições concorrentes
+// dentro do mesmo segundo.
 func generateSessionID() string {
-	return time.Now().Format("20060102150405")
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand.Read só falha em condições extremas de SO; cair para o
+		// timestamp é melhor do que travar o atendimento da requisição.
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// encodeHistory serializa o histórico para JSON (genai.Content já carrega
+// suas próprias tags json, então partes de tool-call fazem o round-trip
+// sem perda).
+func encodeHistory(history []*genai.Content) ([]byte, error) {
+	return json.Marshal(history)
+}
+
+// decodeHistory desfaz encodeHistory.
+func decodeHistory(data []byte) ([]*genai.Content, error) {
+	var history []*genai.Content
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
 }
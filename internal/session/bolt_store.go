@@ -0,0 +1,127 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// sessionsBucket é o único bucket usado pelo BoltStore.
+var sessionsBucket = []byte("sessions")
+
+// boltRecord é a representação em disco de um Record, incluindo o instante
+// de expiração calculado a partir do TTL passado para Put.
+type boltRecord struct {
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	History   []byte    `json:"history"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BoltStore persiste sessões em um arquivo BoltDB local - como o SQLiteStore,
+// sobrevive a restarts de uma única instância sem depender de um serviço
+// externo.
+type BoltStore struct {
+	db *bbolt.DB
+}
+
+// NewBoltStore abre (criando se necessário) o arquivo no path informado e
+// garante que o bucket de sessões exista.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open boltdb file: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(sessionsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create sessions bucket: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Get(ctx context.Context, key string) (*Record, error) {
+	var rec *boltRecord
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(sessionsBucket).Get([]byte(key))
+		if data == nil {
+			return ErrNotFound
+		}
+		rec = &boltRecord{}
+		return json.Unmarshal(data, rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !rec.ExpiresAt.IsZero() && time.Now().After(rec.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+
+	return &Record{
+		SessionID: rec.SessionID,
+		UserID:    rec.UserID,
+		History:   rec.History,
+		UpdatedAt: rec.UpdatedAt,
+	}, nil
+}
+
+func (s *BoltStore) Put(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	data, err := json.Marshal(boltRecord{
+		SessionID: rec.SessionID,
+		UserID:    rec.UserID,
+		History:   rec.History,
+		UpdatedAt: rec.UpdatedAt,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("boltdb encode: %w", err)
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(key), data)
+	})
+}
+
+func (s *BoltStore) Delete(ctx context.Context, key string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(key))
+	})
+}
+
+func (s *BoltStore) List(ctx context.Context) ([]string, error) {
+	var keys []string
+	now := time.Now()
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(k, v []byte) error {
+			var rec boltRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			if rec.ExpiresAt.IsZero() || now.Before(rec.ExpiresAt) {
+				keys = append(keys, string(k))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltdb list: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore é o backend padrão (SESSION_BACKEND=memory): guarda tudo em um
+// map de processo, sem sobreviver a restarts. É o mesmo comportamento que o
+// servidor sempre teve, agora atrás da interface Store.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	rec       *Record
+	expiresAt time.Time
+}
+
+// NewMemoryStore cria um MemoryStore vazio.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string]*memoryEntry)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		return nil, ErrNotFound
+	}
+	return e.rec, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	s.data[key] = &memoryEntry{rec: rec, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, key)
+	return nil
+}
+
+func (s *MemoryStore) List(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]string, 0, len(s.data))
+	for k, e := range s.data {
+		if e.expiresAt.IsZero() || now.Before(e.expiresAt) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sessionKeyPrefix isola as chaves de sessão de outros usos do mesmo Redis.
+const sessionKeyPrefix = "go-adk:session:"
+
+// RedisStore persiste sessões no Redis, permitindo que múltiplas instâncias
+// do servidor compartilhem estado e sobrevivam a restarts. A expiração é
+// delegada ao próprio Redis (TTL passado para Put), então não depende de
+// nenhum processo em background para ser correta.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore conecta a um Redis usando a DSN informada (redis://...) e
+// confirma a conexão com um PING antes de retornar.
+func NewRedisStore(ctx context.Context, dsn string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis DSN: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return &RedisStore{client: client}, nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (*Record, error) {
+	data, err := s.client.Get(ctx, sessionKeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis get: %w", err)
+	}
+
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("redis decode: %w", err)
+	}
+	return &rec, nil
+}
+
+func (s *RedisStore) Put(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("redis encode: %w", err)
+	}
+	if err := s.client.Set(ctx, sessionKeyPrefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("redis set: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, sessionKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("redis del: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) List(ctx context.Context) ([]string, error) {
+	raw, err := s.client.Keys(ctx, sessionKeyPrefix+"*").Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis keys: %w", err)
+	}
+
+	keys := make([]string, len(raw))
+	for i, k := range raw {
+		keys[i] = strings.TrimPrefix(k, sessionKeyPrefix)
+	}
+	return keys, nil
+}
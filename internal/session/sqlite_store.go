@@ -0,0 +1,108 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore persiste sessões em um arquivo SQLite local - útil para uma
+// única instância que precisa sobreviver a restarts sem depender de um
+// serviço externo como Redis.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore abre (criando se necessário) o banco no DSN informado e
+// garante que a tabela de sessões exista.
+func NewSQLiteStore(dsn string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite db: %w", err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	key        TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	history    BLOB NOT NULL,
+	updated_at INTEGER NOT NULL,
+	expires_at INTEGER NOT NULL
+)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create sessions table: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Get(ctx context.Context, key string) (*Record, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT key, user_id, history, updated_at, expires_at FROM sessions WHERE key = ?`, key)
+
+	var rec Record
+	var updatedAt, expiresAt int64
+	if err := row.Scan(&rec.SessionID, &rec.UserID, &rec.History, &updatedAt, &expiresAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("sqlite get: %w", err)
+	}
+
+	if expiresAt != 0 && time.Now().Unix() > expiresAt {
+		return nil, ErrNotFound
+	}
+	rec.UpdatedAt = time.Unix(updatedAt, 0)
+	return &rec, nil
+}
+
+func (s *SQLiteStore) Put(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).Unix()
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO sessions (key, user_id, history, updated_at, expires_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(key) DO UPDATE SET
+	user_id = excluded.user_id,
+	history = excluded.history,
+	updated_at = excluded.updated_at,
+	expires_at = excluded.expires_at`,
+		key, rec.UserID, rec.History, time.Now().Unix(), expiresAt)
+	if err != nil {
+		return fmt.Errorf("sqlite put: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Delete(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("sqlite delete: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) List(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT key FROM sessions WHERE expires_at = 0 OR expires_at > ?`, time.Now().Unix())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite list: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("sqlite scan: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
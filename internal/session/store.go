@@ -0,0 +1,32 @@
+// Package session define um Store plugável para persistir o histórico das
+// ChatSession do servidor, permitindo que ele escale horizontalmente e
+// sobreviva a restarts em vez de manter tudo apenas em memória de processo.
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound é retornado por Get quando a chave não existe ou já expirou.
+var ErrNotFound = errors.New("session: record not found")
+
+// Record é a representação durável de uma ChatSession. History carrega o
+// histórico de genai.Content já serializado em JSON, para que partes de
+// tool-call façam o round-trip sem perda.
+type Record struct {
+	SessionID string
+	UserID    string
+	History   []byte
+	UpdatedAt time.Time
+}
+
+// Store abstrai o backend de persistência de sessões. Implementações devem
+// expirar entradas automaticamente após o TTL passado para Put.
+type Store interface {
+	Get(ctx context.Context, key string) (*Record, error)
+	Put(ctx context.Context, key string, rec *Record, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context) ([]string, error)
+}
@@ -0,0 +1,63 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vitormoschetta/go-adk/internal/config"
+)
+
+// defaultTTL é aplicado quando SESSION_TTL não é informado.
+const defaultTTL = 30 * time.Minute
+
+// NewStoreFromEnv constrói o Store selecionado por SESSION_BACKEND
+// (memory|redis|sqlite|boltdb, padrão memory), usando a DSN correspondente, e
+// retorna o TTL de ociosidade a aplicar nas sessões (SESSION_TTL, padrão
+// 30m). cfg é a mesma Config em camadas (.env.default/.env/${BASE_DIR}/.env/
+// ambiente real) usada pelo resto do servidor.
+func NewStoreFromEnv(ctx context.Context, cfg *config.Config) (Store, time.Duration, error) {
+	ttl := defaultTTL
+	if v := cfg.Get("SESSION_TTL"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid SESSION_TTL %q: %w", v, err)
+		}
+		ttl = parsed
+	}
+
+	switch backend := cfg.Get("SESSION_BACKEND"); backend {
+	case "", "memory":
+		return NewMemoryStore(), ttl, nil
+
+	case "redis":
+		dsn := cfg.Get("REDIS_DSN")
+		if dsn == "" {
+			return nil, 0, fmt.Errorf("REDIS_DSN is required when SESSION_BACKEND=redis")
+		}
+		store, err := NewRedisStore(ctx, dsn)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create redis store: %w", err)
+		}
+		return store, ttl, nil
+
+	case "sqlite":
+		dsn := cfg.GetOrDefault("SQLITE_DSN", "sessions.db")
+		store, err := NewSQLiteStore(dsn)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create sqlite store: %w", err)
+		}
+		return store, ttl, nil
+
+	case "boltdb":
+		dsn := cfg.GetOrDefault("BOLTDB_DSN", "sessions.boltdb")
+		store, err := NewBoltStore(dsn)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create boltdb store: %w", err)
+		}
+		return store, ttl, nil
+
+	default:
+		return nil, 0, fmt.Errorf("unknown SESSION_BACKEND %q (want memory, redis, sqlite or boltdb)", backend)
+	}
+}